@@ -8,10 +8,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"mime"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -26,15 +29,36 @@ const (
 	headerRequestID          = "nexus-request-id"
 	headerLink               = "nexus-link"
 	headerOperationStartTime = "nexus-operation-start-time"
+	// Set on long poll get result responses to indicate the effective max duration the handler waited for.
+	headerMaxWait = "nexus-max-wait"
+	// Absolute deadline of a request in RFC 3339 format, see HTTPClientOptions.SendAbsoluteDeadline.
+	headerDeadline = "nexus-deadline"
+	// Quota information set on resource exhausted responses.
+	headerQuotaRemaining = "nexus-quota-remaining"
+	headerQuotaReset     = "nexus-quota-reset"
+	// Set on start requests to validate the input without starting the operation, see StartOperationOptions.DryRun.
+	headerDryRun = "nexus-dry-run"
+	// Set on unsuccessful operation responses, see UnsuccessfulOperationError.Retryable.
+	headerOperationRetryable = "nexus-operation-retryable"
+	// Prefix for cancel response headers carrying CancelOperationResult.Metadata.
+	headerCancelMetadataPrefix = "nexus-cancel-metadata-"
+	// Prefix for get result response headers carrying Content.Metadata.
+	headerResultMetadataPrefix = "nexus-result-metadata-"
 	// HeaderOperationID is the unique ID returned by the StartOperation response for async operations.
 	// Must be set on callback headers to support completing operations before the start response is received.
 	HeaderOperationID = "nexus-operation-id"
 
 	// HeaderRequestTimeout is the total time to complete a Nexus HTTP request.
+	// See [ParseRequestTimeout] for the formats accepted by handlers created with [NewHTTPHandler].
 	HeaderRequestTimeout = "request-timeout"
 	// HeaderOperationTimeout is the total time to complete a Nexus operation.
 	// Unlike HeaderRequestTimeout, this applies to the whole operation, not just a single HTTP request.
 	HeaderOperationTimeout = "operation-timeout"
+
+	// HeaderTraceParent is the W3C trace context traceparent header, see [ExtractTraceContext].
+	HeaderTraceParent = "traceparent"
+	// HeaderTraceState is the W3C trace context tracestate header, see [ExtractTraceContext].
+	HeaderTraceState = "tracestate"
 )
 
 const contentTypeJSON = "application/json"
@@ -50,7 +74,9 @@ const (
 	statusOperationRunning = http.StatusPreconditionFailed
 	// HTTP status code for failed operation responses.
 	statusOperationFailed = http.StatusFailedDependency
-	StatusUpstreamTimeout = 520
+	// HTTP status code for rejected start operation responses.
+	statusOperationRejected = http.StatusConflict
+	StatusUpstreamTimeout   = 520
 )
 
 // A Failure represents failed handler invocations as well as `failed` or `canceled` operation results. Failures
@@ -63,6 +89,8 @@ type Failure struct {
 	Metadata map[string]string `json:"metadata,omitempty"`
 	// Additional JSON serializable structured data.
 	Details json.RawMessage `json:"details,omitempty"`
+	// The failure that caused this failure, if any. Populated by [ChainFailureConverter].
+	Cause *Failure `json:"cause,omitempty"`
 }
 
 // An error that directly represents a wire representation of [Failure].
@@ -83,6 +111,9 @@ type UnsuccessfulOperationError struct {
 	State OperationState
 	// The underlying cause for this error.
 	Cause error
+	// Optional hint from the handler on whether re-executing the operation may succeed, e.g. false for invalid
+	// arguments and true for a transient dependency failure. Nil if the handler gave no hint.
+	Retryable *bool
 }
 
 // NewFailedOperationError is shorthand for constructing an [UnsuccessfulOperationError] with State set to
@@ -116,15 +147,65 @@ func (e *UnsuccessfulOperationError) Unwrap() error {
 	return e.Cause
 }
 
+// OperationRejectedError indicates that a handler decided not to start an operation, e.g. because a precondition was
+// not met. Return it from a start operation handler to give the caller a clear "not started" outcome, distinct from an
+// invalid request ([HandlerErrorTypeBadRequest]) or an internal error.
+//
+// Unlike an [UnsuccessfulOperationError], which indicates that the operation ran and failed or was canceled, a rejected
+// operation was never started; there is no operation to get the result of or cancel. Whether to retry is up to the
+// caller, e.g. after the precondition has been met.
+//
+// Only valid as a result of starting an operation.
+type OperationRejectedError struct {
+	// The underlying cause for this error.
+	Cause error
+}
+
+// Error implements the error interface.
+func (e *OperationRejectedError) Error() string {
+	if e.Cause == nil {
+		return "operation rejected"
+	}
+	return "operation rejected: " + e.Cause.Error()
+}
+
+// Unwrap returns the cause for use with utilities in the errors package.
+func (e *OperationRejectedError) Unwrap() error {
+	return e.Cause
+}
+
 // ErrOperationStillRunning indicates that an operation is still running while trying to get its result.
 var ErrOperationStillRunning = errors.New("operation still running")
 
+// ErrRequestCanceled indicates that a client request was aborted because its context was canceled or its deadline
+// exceeded. Errors wrapping it also wrap the context's error, allowing to check for [context.Canceled] and
+// [context.DeadlineExceeded] too.
+var ErrRequestCanceled = errors.New("request canceled")
+
 // OperationInfo conveys information about an operation.
 type OperationInfo struct {
 	// ID of the operation.
 	ID string `json:"id"`
 	// State of the operation.
 	State OperationState `json:"state"`
+	// An optional opaque validator for this version of the information, transmitted in the ETag HTTP header. Values
+	// that are not entity tags, e.g. v1 rather than "v1" or W/"v1", are quoted when written to the header, and the
+	// client returns the header value as is.
+	//
+	// Handlers may set it to let clients avoid re-fetching unchanged information: when a get info request's
+	// GetOperationInfoOptions.IfNoneMatch matches the returned ETag, handlers created with [NewHTTPHandler] respond with
+	// 304 Not Modified and [OperationHandle.GetInfo] returns the information cached by the handle. The If-None-Match
+	// header may be "*" or a list of entity tags, compared with the weak comparison of RFC 9110.
+	ETag string `json:"-"`
+}
+
+// CancelOperationResult conveys details about an accepted cancelation request.
+type CancelOperationResult struct {
+	// Links to associate with the cancelation, e.g. to an audit record of the request.
+	Links []Link
+	// Metadata is free-form information about the cancelation. Transmitted as HTTP headers prefixed with
+	// "Nexus-Cancel-Metadata-", keys are case-insensitive and normalized to lower case.
+	Metadata Header
 }
 
 // OperationState represents the variable states of an operation.
@@ -161,9 +242,26 @@ func isMediaTypeOctetStream(contentType string) bool {
 
 // Header is a mapping of string to string.
 // It is used throughout the framework to transmit metadata.
-// The keys should be in lower case form.
+// The keys should be in lower case form, use [NewHeader], [Header.Set] and [Header.With] to normalize keys.
 type Header map[string]string
 
+// NewHeader creates a [Header] from alternating keys and values, transforming keys to their lower case form. Later
+// values override earlier values for the same key.
+//
+//	header := NewHeader("Authorization", "Bearer token", "X-Tenant", "default")
+//
+// Panics if given an odd number of strings.
+func NewHeader(pairs ...string) Header {
+	if len(pairs)%2 == 1 {
+		panic(fmt.Sprintf("nexus: NewHeader got an odd number of strings: %d", len(pairs)))
+	}
+	h := make(Header, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		h.Set(pairs[i], pairs[i+1])
+	}
+	return h
+}
+
 // Get is a case-insensitive key lookup from the header map.
 func (h Header) Get(k string) string {
 	return h[strings.ToLower(k)]
@@ -174,6 +272,19 @@ func (h Header) Set(k, v string) {
 	h[strings.ToLower(k)] = v
 }
 
+// With is like [Header.Set] but returns the header to allow chaining calls.
+//
+//	options.Header.With("Authorization", "Bearer token").With("X-Tenant", "default")
+func (h Header) With(k, v string) Header {
+	h.Set(k, v)
+	return h
+}
+
+// Clone returns a copy of the header, or nil if h is nil.
+func (h Header) Clone() Header {
+	return maps.Clone(h)
+}
+
 func prefixStrippedHTTPHeaderToNexusHeader(httpHeader http.Header, prefix string) Header {
 	header := Header{}
 	for k, v := range httpHeader {
@@ -200,6 +311,20 @@ func addCallbackHeaderToHTTPHeader(nexusHeader Header, httpHeader http.Header) h
 	return httpHeader
 }
 
+func addCancelMetadataToHTTPHeader(nexusHeader Header, httpHeader http.Header) http.Header {
+	for k, v := range nexusHeader {
+		httpHeader.Set("Nexus-Cancel-Metadata-"+k, v)
+	}
+	return httpHeader
+}
+
+func addResultMetadataToHTTPHeader(nexusHeader Header, httpHeader http.Header) http.Header {
+	for k, v := range nexusHeader {
+		httpHeader.Set("Nexus-Result-Metadata-"+k, v)
+	}
+	return httpHeader
+}
+
 func addLinksToHTTPHeader(links []Link, httpHeader http.Header) error {
 	for _, link := range links {
 		encodedLink, err := encodeLink(link)
@@ -227,6 +352,19 @@ func getLinksFromHeader(httpHeader http.Header) ([]Link, error) {
 	return links, nil
 }
 
+// mergeLinks appends the links in additional to links, skipping any link with the same Type and URL as one already
+// present.
+func mergeLinks(links []Link, additional []Link) []Link {
+	for _, link := range additional {
+		if !slices.ContainsFunc(links, func(l Link) bool {
+			return l.Type == link.Type && l.URL.String() == link.URL.String()
+		}) {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
 func httpHeaderToNexusHeader(httpHeader http.Header, excludePrefixes ...string) Header {
 	header := Header{}
 headerLoop:
@@ -250,6 +388,76 @@ func addNexusHeaderToHTTPHeader(nexusHeader Header, httpHeader http.Header) http
 	return httpHeader
 }
 
+func addQuotaToHTTPHeader(quota *Quota, httpHeader http.Header) {
+	httpHeader.Set(headerQuotaRemaining, strconv.Itoa(quota.Remaining))
+	if !quota.ResetAt.IsZero() {
+		httpHeader.Set(headerQuotaReset, quota.ResetAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// getQuotaFromHeader parses quota information from the given header. Returns nil if not present or invalid.
+func getQuotaFromHeader(httpHeader http.Header) *Quota {
+	remaining, err := strconv.Atoi(httpHeader.Get(headerQuotaRemaining))
+	if err != nil {
+		return nil
+	}
+	quota := &Quota{Remaining: remaining}
+	if reset := httpHeader.Get(headerQuotaReset); reset != "" {
+		if quota.ResetAt, err = http.ParseTime(reset); err != nil {
+			return nil
+		}
+	}
+	return quota
+}
+
+// Failure metadata keys for transmitting RetryGuidance.
+const (
+	metadataRetryAfter       = "nexus-retry-after"
+	metadataRetryMaxAttempts = "nexus-retry-max-attempts"
+	metadataRetryJitter      = "nexus-retry-jitter"
+)
+
+// addRetryGuidanceToFailureMetadata returns a copy of the given metadata with the retry guidance added.
+func addRetryGuidanceToFailureMetadata(guidance *RetryGuidance, metadata map[string]string) map[string]string {
+	metadata = maps.Clone(metadata)
+	if metadata == nil {
+		metadata = make(map[string]string, 3)
+	}
+	metadata[metadataRetryAfter] = formatDuration(guidance.RetryAfter)
+	if guidance.MaxAttempts > 0 {
+		metadata[metadataRetryMaxAttempts] = strconv.Itoa(guidance.MaxAttempts)
+	}
+	if guidance.Jitter > 0 {
+		metadata[metadataRetryJitter] = formatDuration(guidance.Jitter)
+	}
+	return metadata
+}
+
+// getRetryGuidanceFromFailureMetadata parses retry guidance from failure metadata. Returns nil if not present or
+// invalid.
+func getRetryGuidanceFromFailureMetadata(metadata map[string]string) *RetryGuidance {
+	retryAfter, ok := metadata[metadataRetryAfter]
+	if !ok {
+		return nil
+	}
+	var guidance RetryGuidance
+	var err error
+	if guidance.RetryAfter, err = parseDuration(retryAfter); err != nil {
+		return nil
+	}
+	if maxAttempts, ok := metadata[metadataRetryMaxAttempts]; ok {
+		if guidance.MaxAttempts, err = strconv.Atoi(maxAttempts); err != nil {
+			return nil
+		}
+	}
+	if jitter, ok := metadata[metadataRetryJitter]; ok {
+		if guidance.Jitter, err = parseDuration(jitter); err != nil {
+			return nil
+		}
+	}
+	return &guidance
+}
+
 func addContextTimeoutToHTTPHeader(ctx context.Context, httpHeader http.Header) http.Header {
 	deadline, ok := ctx.Deadline()
 	if !ok {
@@ -400,14 +608,40 @@ func parseDuration(value string) (time.Duration, error) {
 	}
 
 	switch m[2] {
-	case "ms":
-		return time.Millisecond * time.Duration(v), nil
 	case "s":
-		return time.Millisecond * time.Duration(v*1e3), nil
+		v *= 1e3
 	case "m":
-		return time.Millisecond * time.Duration(v*1e3*60), nil
+		v *= 1e3 * 60
+	}
+	if v > math.MaxInt64/float64(time.Millisecond) {
+		return 0, fmt.Errorf("duration out of range: %q", value)
+	}
+	return time.Millisecond * time.Duration(v), nil
+}
+
+// ParseRequestTimeout parses the value of a Request-Timeout header. For interoperability with other Nexus
+// implementations, the following formats are accepted:
+//
+//   - The Nexus format, a number followed by a "ms", "s", or "m" unit, e.g. "500ms" or "1.5s".
+//   - A Go duration string as produced by [time.Duration.String], e.g. "1m30s".
+//   - A plain integer, interpreted as milliseconds, e.g. "500".
+//
+// Negative durations are rejected.
+func ParseRequestTimeout(value string) (time.Duration, error) {
+	if d, err := parseDuration(value); err == nil {
+		return d, nil
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if ms < 0 || ms > math.MaxInt64/int64(time.Millisecond) {
+			return 0, fmt.Errorf("invalid request timeout: %q", value)
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid request timeout: %q", value)
 	}
-	panic("unreachable")
+	return d, nil
 }
 
 // formatDuration converts a duration into a string representation in millisecond resolution.
@@ -58,7 +58,7 @@ func TestFailure_JSONMarshalling(t *testing.T) {
 		t.Run(tc.message, func(t *testing.T) {
 			serializedDetails, err := json.MarshalIndent(tc.details, "", "\t")
 			require.NoError(t, err)
-			source, err := json.MarshalIndent(Failure{tc.message, tc.metadata, serializedDetails}, "", "\t")
+			source, err := json.MarshalIndent(Failure{Message: tc.message, Metadata: tc.metadata, Details: serializedDetails}, "", "\t")
 			require.NoError(t, err)
 			require.Equal(t, tc.serialized, string(source))
 
@@ -529,3 +529,44 @@ func TestParseDuration(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1300*time.Millisecond, d)
 }
+
+func TestParseRequestTimeout(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"500ms", 500 * time.Millisecond},
+		{"1.5s", 1500 * time.Millisecond},
+		{"2m", 2 * time.Minute},
+		{"1m30s", 90 * time.Second},
+		{"250us", 250 * time.Microsecond},
+		{"500", 500 * time.Millisecond},
+		{"0", 0},
+	}
+	for _, c := range cases {
+		d, err := ParseRequestTimeout(c.value)
+		require.NoError(t, err, c.value)
+		require.Equal(t, c.expected, d, c.value)
+	}
+
+	for _, value := range []string{"", "invalid", "-5", "-1s", "10 ms", "9223372036855", "9223372036855ms", "2562048h"} {
+		_, err := ParseRequestTimeout(value)
+		require.ErrorContains(t, err, "invalid request timeout", value)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	header := NewHeader("Authorization", "token", "X-Tenant", "a", "x-tenant", "b")
+	require.Equal(t, Header{"authorization": "token", "x-tenant": "b"}, header)
+	require.Equal(t, "token", header.Get("AUTHORIZATION"))
+	require.Equal(t, "b", header.Get("X-Tenant"))
+
+	clone := header.Clone().With("Content-Type", "application/json").With("X-TENANT", "c")
+	require.Equal(t, "application/json", clone.Get("content-type"))
+	require.Equal(t, "c", clone.Get("x-tenant"))
+	require.Equal(t, "b", header.Get("x-tenant"))
+	require.NotContains(t, header, "content-type")
+
+	require.Nil(t, Header(nil).Clone())
+	require.Panics(t, func() { NewHeader("key") })
+}
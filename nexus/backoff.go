@@ -0,0 +1,57 @@
+package nexus
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff computes exponentially growing delays between attempts, e.g. for polling or retries.
+//
+//	backoff := Backoff{InitialInterval: 100 * time.Millisecond, MaxInterval: 10 * time.Second, Jitter: 0.2}
+//	for attempt := 1; ; attempt++ {
+//		if err := try(); err == nil {
+//			break
+//		}
+//		time.Sleep(backoff.Delay(attempt))
+//	}
+type Backoff struct {
+	// Delay after the first attempt.
+	InitialInterval time.Duration
+	// Maximum delay, including jitter. Unlimited if zero or negative.
+	MaxInterval time.Duration
+	// Multiplier applied to the delay after each attempt. Defaults to 2, values less than 1 are treated as 1, i.e. a
+	// constant delay.
+	Coefficient float64
+	// Fraction of the delay to randomize, between 0 and 1. A jitter of 0.2 spreads delays uniformly within 20% of the
+	// computed delay in either direction. No jitter if zero.
+	Jitter float64
+}
+
+// Delay returns the delay after the given attempt, starting at 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	if b.InitialInterval <= 0 {
+		return 0
+	}
+	coefficient := b.Coefficient
+	if coefficient == 0 {
+		coefficient = 2
+	} else if coefficient < 1 {
+		coefficient = 1
+	}
+	delay := float64(b.InitialInterval) * math.Pow(coefficient, float64(max(attempt, 1)-1))
+	if b.MaxInterval > 0 {
+		delay = min(delay, float64(b.MaxInterval))
+	}
+	if jitter := min(max(b.Jitter, 0), 1); jitter > 0 {
+		delay *= 1 + jitter*(2*rand.Float64()-1)
+	}
+	if b.MaxInterval > 0 {
+		delay = min(delay, float64(b.MaxInterval))
+	}
+	// Avoid overflowing when converting back to a Duration.
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,51 @@
+package nexus
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_BoundedGrowth(t *testing.T) {
+	backoff := Backoff{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Second}
+	require.Equal(t, 100*time.Millisecond, backoff.Delay(0))
+	require.Equal(t, 100*time.Millisecond, backoff.Delay(1))
+	require.Equal(t, 200*time.Millisecond, backoff.Delay(2))
+	require.Equal(t, 400*time.Millisecond, backoff.Delay(3))
+	require.Equal(t, 800*time.Millisecond, backoff.Delay(4))
+	require.Equal(t, time.Second, backoff.Delay(5))
+	require.Equal(t, time.Second, backoff.Delay(math.MaxInt))
+
+	constant := Backoff{InitialInterval: 100 * time.Millisecond, Coefficient: 1}
+	require.Equal(t, 100*time.Millisecond, constant.Delay(10))
+
+	unbounded := Backoff{InitialInterval: time.Second, Coefficient: 10}
+	require.Equal(t, time.Duration(math.MaxInt64), unbounded.Delay(math.MaxInt))
+
+	require.Equal(t, time.Duration(0), Backoff{}.Delay(3))
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	backoff := Backoff{InitialInterval: time.Second, Coefficient: 1, Jitter: 0.2}
+	const samples = 1000
+	var sum time.Duration
+	seen := make(map[time.Duration]struct{})
+	for i := 0; i < samples; i++ {
+		delay := backoff.Delay(1)
+		require.GreaterOrEqual(t, delay, 800*time.Millisecond)
+		require.LessOrEqual(t, delay, 1200*time.Millisecond)
+		sum += delay
+		seen[delay] = struct{}{}
+	}
+	require.Greater(t, len(seen), samples/2)
+	require.InDelta(t, float64(time.Second), float64(sum/samples), float64(50*time.Millisecond))
+
+	capped := Backoff{InitialInterval: time.Second, MaxInterval: time.Second, Jitter: 1}
+	for i := 0; i < samples; i++ {
+		delay := capped.Delay(3)
+		require.GreaterOrEqual(t, delay, time.Duration(0))
+		require.LessOrEqual(t, delay, time.Second)
+	}
+}
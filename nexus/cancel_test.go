@@ -2,6 +2,8 @@ package nexus
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -19,23 +21,23 @@ func (h *asyncWithCancelHandler) StartOperation(ctx context.Context, service, op
 	}, nil
 }
 
-func (h *asyncWithCancelHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) error {
+func (h *asyncWithCancelHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) (*CancelOperationResult, error) {
 	if service != testService {
-		return HandlerErrorf(HandlerErrorTypeBadRequest, "unexpected service: %s", service)
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "unexpected service: %s", service)
 	}
 	if operation != "f/o/o" {
-		return HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation to be 'foo', got: %s", operation)
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation to be 'foo', got: %s", operation)
 	}
 	if operationID != "a/sync" {
-		return HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation ID to be 'async', got: %s", operationID)
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation ID to be 'async', got: %s", operationID)
 	}
 	if h.expectHeader && options.Header.Get("foo") != "bar" {
-		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid 'foo' request header")
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid 'foo' request header")
 	}
 	if options.Header.Get("User-Agent") != userAgent {
-		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid 'User-Agent' header: %q", options.Header.Get("User-Agent"))
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid 'User-Agent' header: %q", options.Header.Get("User-Agent"))
 	}
-	return nil
+	return nil, nil
 }
 
 func TestCancel_HandleFromStart(t *testing.T) {
@@ -46,7 +48,7 @@ func TestCancel_HandleFromStart(t *testing.T) {
 	require.NoError(t, err)
 	handle := result.Pending
 	require.NotNil(t, handle)
-	err = handle.Cancel(ctx, CancelOperationOptions{
+	_, err = handle.Cancel(ctx, CancelOperationOptions{
 		Header: Header{"foo": "bar"},
 	})
 	require.NoError(t, err)
@@ -58,7 +60,7 @@ func TestCancel_HandleFromClient(t *testing.T) {
 
 	handle, err := client.NewHandle("f/o/o", "a/sync")
 	require.NoError(t, err)
-	err = handle.Cancel(ctx, CancelOperationOptions{})
+	_, err = handle.Cancel(ctx, CancelOperationOptions{})
 	require.NoError(t, err)
 }
 
@@ -73,19 +75,19 @@ func (h *echoTimeoutAsyncWithCancelHandler) StartOperation(ctx context.Context,
 	}, nil
 }
 
-func (h *echoTimeoutAsyncWithCancelHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) error {
+func (h *echoTimeoutAsyncWithCancelHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) (*CancelOperationResult, error) {
 	deadline, set := ctx.Deadline()
 	if h.expectedTimeout > 0 && !set {
-		return HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation to have timeout set but context has no deadline")
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation to have timeout set but context has no deadline")
 	}
 	if h.expectedTimeout <= 0 && set {
-		return HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation to have no timeout but context has deadline set")
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation to have no timeout but context has deadline set")
 	}
 	timeout := time.Until(deadline)
 	if timeout > h.expectedTimeout {
-		return HandlerErrorf(HandlerErrorTypeBadRequest, "operation has timeout (%s) greater than expected (%s)", timeout.String(), h.expectedTimeout.String())
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "operation has timeout (%s) greater than expected (%s)", timeout.String(), h.expectedTimeout.String())
 	}
-	return nil
+	return nil, nil
 }
 
 func TestCancel_ContextDeadlinePropagated(t *testing.T) {
@@ -94,7 +96,7 @@ func TestCancel_ContextDeadlinePropagated(t *testing.T) {
 
 	handle, err := client.NewHandle("foo", "timeout")
 	require.NoError(t, err)
-	err = handle.Cancel(ctx, CancelOperationOptions{})
+	_, err = handle.Cancel(ctx, CancelOperationOptions{})
 	require.NoError(t, err)
 }
 
@@ -106,7 +108,7 @@ func TestCancel_RequestTimeoutHeaderOverridesContextDeadline(t *testing.T) {
 
 	handle, err := client.NewHandle("foo", "timeout")
 	require.NoError(t, err)
-	err = handle.Cancel(ctx, CancelOperationOptions{Header: Header{HeaderRequestTimeout: formatDuration(timeout)}})
+	_, err = handle.Cancel(ctx, CancelOperationOptions{Header: Header{HeaderRequestTimeout: formatDuration(timeout)}})
 	require.NoError(t, err)
 }
 
@@ -116,6 +118,77 @@ func TestCancel_TimeoutNotPropagated(t *testing.T) {
 
 	handle, err := client.NewHandle("foo", "timeout")
 	require.NoError(t, err)
-	err = handle.Cancel(context.Background(), CancelOperationOptions{})
+	_, err = handle.Cancel(context.Background(), CancelOperationOptions{})
 	require.NoError(t, err)
 }
+
+func TestCancel_WithDelete(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithCancelHandler{expectHeader: true})
+	defer teardown()
+
+	var method string
+	client.options.CancelWithDelete = true
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		method = r.Method
+		return http.DefaultClient.Do(r)
+	}
+
+	handle, err := client.NewHandle("f/o/o", "a/sync")
+	require.NoError(t, err)
+	_, err = handle.Cancel(ctx, CancelOperationOptions{
+		Header: Header{"foo": "bar"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "DELETE", method)
+}
+
+type cancelWithResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *cancelWithResultHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) (*CancelOperationResult, error) {
+	return &CancelOperationResult{
+		Links: []Link{{
+			URL: &url.URL{
+				Scheme:   "https",
+				Host:     "example.com",
+				Path:     "/audit/" + operationID,
+				RawQuery: "reason=user",
+			},
+			Type: "audit.Record",
+		}},
+		Metadata: Header{"accepted-by": "worker-1"},
+	}, nil
+}
+
+func TestCancel_Result(t *testing.T) {
+	ctx, client, teardown := setup(t, &cancelWithResultHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	result, err := handle.Cancel(ctx, CancelOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []Link{{
+		URL: &url.URL{
+			Scheme:   "https",
+			Host:     "example.com",
+			Path:     "/audit/a/sync",
+			RawQuery: "reason=user",
+		},
+		Type: "audit.Record",
+	}}, result.Links)
+	require.Equal(t, Header{"accepted-by": "worker-1"}, result.Metadata)
+}
+
+func TestCancel_NilResult(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithCancelHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("f/o/o", "a/sync")
+	require.NoError(t, err)
+	result, err := handle.Cancel(ctx, CancelOperationOptions{})
+	require.NoError(t, err)
+	require.Empty(t, result.Links)
+	require.Empty(t, result.Metadata)
+}
@@ -10,8 +10,10 @@ import (
 	"maps"
 	"math"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -32,6 +34,107 @@ type HTTPClientOptions struct {
 	// A [FailureConverter] to convert a [Failure] instance to and from an [error]. Defaults to
 	// [DefaultFailureConverter].
 	FailureConverter FailureConverter
+	// An optional function to transform operation inputs before they are sent to the server, e.g. to inject tenant
+	// fields or encrypt payloads.
+	//
+	// The transformer is invoked by [HTTPClient.StartOperation] and [HTTPClient.ExecuteOperation] before the input is
+	// serialized with the configured Serializer. Inputs provided as [*Content] or [*Reader] are passed to the
+	// transformer as well and bypass serialization if returned unchanged. A transformer that replaces a [*Reader]
+	// input is responsible for closing it.
+	InputTransformer func(ctx context.Context, input any) (any, error)
+	// Hedging configures hedged get result requests to reduce tail latency. Disabled by default.
+	Hedging HedgingOptions
+	// Maximum number of requests issued by a single long polling [OperationHandle.GetResult] call. Once reached,
+	// GetResult returns [ErrOperationStillRunning]. Unlimited by default.
+	MaxPollIterations int
+	// Backoff between consecutive long poll get result requests that the server responds to with a timeout, e.g. to
+	// avoid hammering a misconfigured load balancer that times out requests early. The delay after each timeout is
+	// measured from the start of the timed out request.
+	//
+	// Defaults to a constant 100 milliseconds if InitialInterval is zero, set InitialInterval to a negative value to
+	// disable.
+	LongPollBackoff Backoff
+	// An optional [httptrace.ClientTrace] installed into the context of every request issued by the client and its
+	// handles, to observe DNS, connect, TLS handshake and connection reuse events.
+	//
+	// The trace is ignored if a custom HTTPCaller does not issue the request with its context.
+	ClientTrace *httptrace.ClientTrace
+	// If set, [OperationHandle.Cancel] requests cancelation with DELETE /{service}/{operation}/{operation_id} instead of
+	// POST /{service}/{operation}/{operation_id}/cancel, for interop with gateways that map cancelation to deleting the
+	// operation resource. Handlers created with [NewHTTPHandler] accept both forms.
+	CancelWithDelete bool
+	// If set, requests made with a context deadline carry the absolute deadline in the Nexus-Deadline header, in
+	// addition to the relative Request-Timeout header. Handlers created with [NewHTTPHandler] use the shorter of the
+	// two, improving deadline fidelity when proxies add latency. Requires roughly synchronized clocks, see
+	// [HandlerOptions.DeadlineClockSkewTolerance].
+	SendAbsoluteDeadline bool
+	// If set, errors returned by client and [OperationHandle] methods are wrapped in an [OperationCallError] that
+	// identifies the service and operation that produced them.
+	IncludeOperationInErrors bool
+	// An optional function to normalize the terminal outcome of operations, e.g. to treat certain failures as
+	// successes. Applied to results returned by [OperationHandle.GetResult], [GetOperationResultWithDetails], and
+	// [HTTPClient.ExecuteOperation].
+	//
+	// The mapper is called with [OperationStateSucceeded] and a nil error for successful results, and with the state
+	// and cause of an [UnsuccessfulOperationError] otherwise. If it returns [OperationStateSucceeded], the result is
+	// successful and the returned error is ignored; an outcome mapped from an unsuccessful state has a zero value
+	// result. Otherwise, an [UnsuccessfulOperationError] with the returned state and error as its cause is returned and
+	// the result of a successful outcome is discarded. The error retains the Retryable hint of the original outcome.
+	//
+	// ⚠️ Mapping failures to successes hides them from all callers of the client. Keep mappers narrow, matching on
+	// specific failures only.
+	OutcomeMapper func(state OperationState, err error) (OperationState, error)
+	// A function to generate request IDs for start requests that don't set StartOperationOptions.RequestID, e.g. to
+	// correlate request IDs with an external trace. Defaults to generating v4 UUIDs.
+	RequestIDGenerator func() string
+	// Maximum size in bytes of response bodies read by the client. Bodies that exceed it fail with an
+	// [UnexpectedResponseError], including successful results, which fail to be consumed. Unlimited by default.
+	MaxResponseBodySize int64
+	// Timeouts applied to the calls made for specific operations, keyed by operation name, to centralize enforcement of
+	// per-operation SLOs. Operations not listed use DefaultOperationTimeout. Set an operation's timeout to a negative
+	// value to exempt it from DefaultOperationTimeout.
+	//
+	// The timeout is applied to the context of each start, get info, cancel, register callback and get result call,
+	// and never extends a shorter context deadline. Long polling get result calls are given the wait duration on top of
+	// the timeout. The result of a start or get result call returned as a [LazyValue] must be consumed within the
+	// timeout.
+	OperationTimeouts map[string]time.Duration
+	// Timeout applied to calls for operations not listed in OperationTimeouts. No timeout by default.
+	DefaultOperationTimeout time.Duration
+	// Header fields attached to every request made by the client, e.g. an authorization or tenant header. Header
+	// fields set per call, e.g. via StartOperationOptions.Header, and SDK-provided values for the same key take
+	// precedence. Callback headers are not affected.
+	//
+	// Header keys with the "content-" prefix are reserved for [Serializer] headers and should not be set.
+	DefaultHeader Header
+	// An optional [ClientObserver] notified when each client and [OperationHandle] call completes, e.g. to record
+	// latency and outcome metrics.
+	Observer ClientObserver
+	// An optional function to map the HTTP status codes of unsuccessful responses to handler error types, for
+	// gateways with nonstandard status conventions, e.g. that rewrite some statuses. Responses with status codes for
+	// which it returns false fail with an [UnexpectedResponseError]. Handlers must use a matching
+	// HandlerOptions.StatusCodeFromHandlerErrorType mapping.
+	//
+	// Defaults to [DefaultHandlerErrorTypeFromStatusCode]. Overrides may fall back to it for status codes they don't
+	// handle.
+	HandlerErrorTypeFromStatusCode func(statusCode int) (HandlerErrorType, bool)
+}
+
+// HedgingOptions configure hedged requests for [OperationHandle.GetResult].
+//
+// When enabled, if a get result request does not complete within Delay, an additional identical request is issued and
+// the first successful or terminal response to arrive (a result, an unsuccessful operation, or an operation that is
+// still running) is returned. Responses of the remaining requests are discarded and their requests canceled. Requests
+// that fail, e.g. due to a transport or server error, are hedged without waiting for Delay, and an error is returned
+// only if all MaxParallel requests fail.
+//
+// Hedging only applies to requests that do not long poll (GetOperationResultOptions.Wait is 0), since the latency of a
+// long poll request is dictated by the operation rather than the server.
+type HedgingOptions struct {
+	// Time to wait for a response before issuing an additional request. Hedging is disabled if not positive.
+	Delay time.Duration
+	// Maximum number of concurrent requests, including the initial request. Defaults to 2.
+	MaxParallel int
 }
 
 // User-Agent header set on HTTP requests.
@@ -54,7 +157,11 @@ type UnexpectedResponseError struct {
 	// Additional transport specific details.
 	// For HTTP, this would include the HTTP response. The response body will have already been read into memory and
 	// does not need to be closed.
+	//
+	// Prefer the StatusCode and ResponseBody methods, which don't require asserting the type of Details.
 	Details any
+
+	body []byte
 }
 
 // Error implements the error interface.
@@ -62,6 +169,73 @@ func (e *UnexpectedResponseError) Error() string {
 	return e.Message
 }
 
+// StatusCode returns the HTTP status code of the unexpected response. Returns false if Details is not an
+// [*http.Response].
+func (e *UnexpectedResponseError) StatusCode() (int, bool) {
+	response, ok := e.Details.(*http.Response)
+	if !ok || response == nil {
+		return 0, false
+	}
+	return response.StatusCode, true
+}
+
+// ResponseBody returns the body of the unexpected response, as read into memory by the client. Returns nil if the
+// response had no body or the error was not constructed by the client.
+func (e *UnexpectedResponseError) ResponseBody() []byte {
+	return e.body
+}
+
+// OperationCallError annotates an error returned by a client or [OperationHandle] method with the service and
+// operation that produced it. Returned when HTTPClientOptions.IncludeOperationInErrors is set.
+//
+// The wrapped error can be inspected with [errors.As] and [errors.Is] as usual.
+type OperationCallError struct {
+	// Service name.
+	Service string
+	// Operation name.
+	Operation string
+	// The underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *OperationCallError) Error() string {
+	return fmt.Sprintf("%s/%s: %v", e.Service, e.Operation, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *OperationCallError) Unwrap() error {
+	return e.Err
+}
+
+// withOperationTimeout applies the timeout configured for the given operation to ctx, if any. A positive wait duration
+// is added to the timeout.
+func (c *HTTPClient) withOperationTimeout(ctx context.Context, operation string, wait time.Duration) (context.Context, context.CancelFunc) {
+	timeout, ok := c.options.OperationTimeouts[operation]
+	if !ok {
+		timeout = c.options.DefaultOperationTimeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if wait > 0 {
+		if wait > math.MaxInt64-timeout {
+			// Waiting indefinitely, e.g. in ExecuteOperation.
+			return ctx, func() {}
+		}
+		timeout += wait
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// annotateError wraps a non nil err in an [OperationCallError] if enabled by the client's options.
+func (c *HTTPClient) annotateError(operation string, err error) error {
+	if err == nil || !c.options.IncludeOperationInErrors {
+		return err
+	}
+	return &OperationCallError{Service: c.options.Service, Operation: operation, Err: err}
+}
+
 func newUnexpectedResponseError(message string, response *http.Response, body []byte) error {
 	var failure *Failure
 	if isMediaTypeJSON(response.Header.Get("Content-Type")) {
@@ -74,6 +248,7 @@ func newUnexpectedResponseError(message string, response *http.Response, body []
 		Message: message,
 		Details: response,
 		Failure: failure,
+		body:    body,
 	}
 }
 
@@ -120,6 +295,18 @@ func NewHTTPClient(options HTTPClientOptions) (*HTTPClient, error) {
 	if options.FailureConverter == nil {
 		options.FailureConverter = defaultFailureConverter
 	}
+	if options.LongPollBackoff.InitialInterval == 0 {
+		options.LongPollBackoff = Backoff{InitialInterval: 100 * time.Millisecond, Coefficient: 1}
+	}
+	if options.HandlerErrorTypeFromStatusCode == nil {
+		options.HandlerErrorTypeFromStatusCode = DefaultHandlerErrorTypeFromStatusCode
+	}
+	if options.Hedging.MaxParallel == 0 {
+		options.Hedging.MaxParallel = 2
+	}
+	if options.RequestIDGenerator == nil {
+		options.RequestIDGenerator = uuid.NewString
+	}
 
 	return &HTTPClient{
 		options:        options,
@@ -128,7 +315,7 @@ func NewHTTPClient(options HTTPClientOptions) (*HTTPClient, error) {
 }
 
 // ClientStartOperationResult is the return type of [HTTPClient.StartOperation].
-// One and only one of Successful or Pending will be non-nil.
+// One and only one of Successful or Pending will be non-nil, unless DryRun is set.
 type ClientStartOperationResult[T any] struct {
 	// Set when start completes synchronously and successfully.
 	//
@@ -140,6 +327,22 @@ type ClientStartOperationResult[T any] struct {
 	Pending *OperationHandle[T]
 	// Links contain information about the operations done by the handler.
 	Links []Link
+	// Set when a start request with [StartOperationOptions.DryRun] passed validation. Successful and Pending are both
+	// unset in this case.
+	DryRun bool
+}
+
+// ClientGetOperationResult is the return type of [GetOperationResultWithDetails].
+type ClientGetOperationResult[T any] struct {
+	// The operation's result.
+	//
+	// If T is a [LazyValue], ensure that your consume it or read the underlying content in its entirety and close it to
+	// free up the underlying connection.
+	Result T
+	// Links attached by the handler to the result response.
+	Links []Link
+	// Metadata attached by the handler to the result response, see Content.Metadata.
+	Metadata Header
 }
 
 // StartOperation calls the configured Nexus endpoint to start an operation.
@@ -156,19 +359,58 @@ type ClientStartOperationResult[T any] struct {
 //  3. The operation was unsuccessful. The returned result will be nil and error will be an
 //     [UnsuccessfulOperationError].
 //
-//  4. Any other error.
+//  4. The handler rejected the operation without starting it. The returned result will be nil and error will be an
+//     [OperationRejectedError].
+//
+//  5. Any other error.
 func (c *HTTPClient) StartOperation(
 	ctx context.Context,
 	operation string,
 	input any,
 	options StartOperationOptions,
 ) (*ClientStartOperationResult[*LazyValue], error) {
+	start := time.Now()
+	ctx, cancel := c.withOperationTimeout(ctx, operation, 0)
+	result, err := c.startOperation(ctx, operation, input, options)
+	outcome := outcomeFromError(err)
+	if err == nil && result.Pending != nil {
+		outcome.Kind = OutcomePending
+	}
+	c.observe(ClientObserver.StartOperationDone, operation, start, outcome)
+	if err == nil && result.Successful != nil {
+		result.Successful.Reader.ReadCloser = &cancelOnCloseBody{result.Successful.Reader.ReadCloser, cancel}
+	} else {
+		cancel()
+	}
+	return result, c.annotateError(operation, err)
+}
+
+func (c *HTTPClient) startOperation(
+	ctx context.Context,
+	operation string,
+	input any,
+	options StartOperationOptions,
+) (*ClientStartOperationResult[*LazyValue], error) {
+	if c.options.InputTransformer != nil {
+		var err error
+		if input, err = c.options.InputTransformer(ctx, input); err != nil {
+			return nil, err
+		}
+	}
 	var reader *Reader
+	_, isContent := input.(*Content)
 	if r, ok := input.(*Reader); ok {
 		// Close the input reader in case we error before sending the HTTP request (which may double close but
 		// that's fine since we ignore the error).
 		defer r.Close()
 		reader = r
+	} else if s, ok := c.options.Serializer.(StreamSerializer); ok && !isContent {
+		body, header, err := s.SerializeStream(input)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		reader = &Reader{body, header}
 	} else {
 		content, ok := input.(*Content)
 		if !ok {
@@ -197,13 +439,13 @@ func (c *HTTPClient) StartOperation(
 		q.Set(queryCallbackURL, options.CallbackURL)
 		url.RawQuery = q.Encode()
 	}
-	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), reader)
+	request, err := c.newRequest(ctx, "POST", url.String(), reader)
 	if err != nil {
 		return nil, err
 	}
 
 	if options.RequestID == "" {
-		options.RequestID = uuid.NewString()
+		options.RequestID = c.options.RequestIDGenerator()
 	}
 	request.Header.Set(headerRequestID, options.RequestID)
 	request.Header.Set(headerUserAgent, userAgent)
@@ -212,10 +454,14 @@ func (c *HTTPClient) StartOperation(
 	if err := addLinksToHTTPHeader(options.Links, request.Header); err != nil {
 		return nil, fmt.Errorf("failed to serialize links into header: %w", err)
 	}
-	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	c.addDeadlineToHTTPHeader(ctx, request.Header)
+	if options.DryRun {
+		request.Header.Set(headerDryRun, "true")
+	}
+	addAcceptToHTTPHeader(c.options.Serializer, request.Header)
 	addNexusHeaderToHTTPHeader(options.Header, request.Header)
 
-	response, err := c.options.HTTPCaller(request)
+	response, err := c.sendRequest(request)
 	if err != nil {
 		return nil, err
 	}
@@ -223,7 +469,7 @@ func (c *HTTPClient) StartOperation(
 	links, err := getLinksFromHeader(response.Header)
 	if err != nil {
 		// Have to read body here to check if it is a Failure.
-		body, err := readAndReplaceBody(response)
+		body, err := c.readAndReplaceBody(response)
 		if err != nil {
 			return nil, err
 		}
@@ -244,7 +490,7 @@ func (c *HTTPClient) StartOperation(
 			Successful: &LazyValue{
 				serializer: c.options.Serializer,
 				Reader: &Reader{
-					response.Body,
+					c.limitResponseBody(response.Body),
 					prefixStrippedHTTPHeaderToNexusHeader(response.Header, "content-"),
 				},
 			},
@@ -253,12 +499,17 @@ func (c *HTTPClient) StartOperation(
 	}
 
 	// Do this once here and make sure it doesn't leak.
-	body, err := readAndReplaceBody(response)
+	body, err := c.readAndReplaceBody(response)
 	if err != nil {
 		return nil, err
 	}
 
 	switch response.StatusCode {
+	case http.StatusNoContent:
+		if !options.DryRun {
+			return nil, c.bestEffortHandlerErrorFromResponse(response, body)
+		}
+		return &ClientStartOperationResult[*LazyValue]{DryRun: true, Links: links}, nil
 	case http.StatusCreated:
 		info, err := operationInfoFromResponse(response, body)
 		if err != nil {
@@ -275,6 +526,9 @@ func (c *HTTPClient) StartOperation(
 			},
 			Links: links,
 		}, nil
+	case statusOperationRejected:
+		failureErr := c.failureErrorFromResponseOrDefault(response, body, "operation rejected")
+		return nil, &OperationRejectedError{Cause: failureErr}
 	case statusOperationFailed:
 		state, err := getUnsuccessfulStateFromHeader(response, body)
 		if err != nil {
@@ -288,14 +542,51 @@ func (c *HTTPClient) StartOperation(
 
 		failureErr := c.options.FailureConverter.FailureToError(failure)
 		return nil, &UnsuccessfulOperationError{
-			State: state,
-			Cause: failureErr,
+			State:     state,
+			Cause:     failureErr,
+			Retryable: getRetryableFromHeader(response.Header),
 		}
 	default:
 		return nil, c.bestEffortHandlerErrorFromResponse(response, body)
 	}
 }
 
+// StartOperationHandle is a variant of [HTTPClient.StartOperation] that always returns an [OperationHandle], unifying
+// the calling code path for synchronous and asynchronous operations.
+//
+// The start result is returned alongside the handle, carrying the response links. For asynchronous operations, the
+// returned handle is the same as ClientStartOperationResult.Pending.
+//
+// For operations that complete synchronously, the returned handle is pre-resolved and does not issue any network
+// requests:
+//   - GetResult returns the inline result, which is also available as ClientStartOperationResult.Successful. The result
+//     can only be consumed once, either via the handle or the start result, and must be consumed to free up the
+//     underlying connection.
+//   - GetInfo returns an [OperationInfo] in the succeeded state.
+//   - Cancel is a no-op.
+//   - RegisterCallback fails since the operation has already completed.
+//
+// The handle's ID is empty for synchronously completed operations since the handler doesn't assign one.
+func (c *HTTPClient) StartOperationHandle(ctx context.Context, operation string, input any, options StartOperationOptions) (*OperationHandle[*LazyValue], *ClientStartOperationResult[*LazyValue], error) {
+	if options.DryRun {
+		return nil, nil, errors.New("dry run is not supported by StartOperationHandle, use StartOperation instead")
+	}
+	result, err := c.StartOperation(ctx, operation, input, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.Pending != nil {
+		return result.Pending, result, nil
+	}
+	handle := &OperationHandle[*LazyValue]{
+		Operation: operation,
+		client:    c,
+		resolved:  true,
+	}
+	handle.result.Store(result.Successful)
+	return handle, result, nil
+}
+
 // ExecuteOperationOptions are options for [HTTPClient.ExecuteOperation].
 type ExecuteOperationOptions struct {
 	// Callback URL to provide to the handle for receiving async operation completions. Optional.
@@ -306,7 +597,7 @@ type ExecuteOperationOptions struct {
 	// asynchronous operation completes.
 	CallbackHeader Header
 	// Request ID that may be used by the server handler to dedupe this start request.
-	// By default a v4 UUID will be generated by the client.
+	// By default the client generates one with HTTPClientOptions.RequestIDGenerator, a v4 UUID unless customized.
 	RequestID string
 	// Links contain arbitrary caller information. Handlers may use these links as
 	// metadata on resources associated with and operation.
@@ -322,6 +613,16 @@ type ExecuteOperationOptions struct {
 	//
 	// ⚠ NOTE: unlike GetOperationResultOptions.Wait, zero and negative values are considered effectively infinite.
 	Wait time.Duration
+	// Optional callback for reporting the progress of asynchronous operations while waiting for their completion.
+	//
+	// While waiting, the client fetches the operation's information every ProgressInterval, concurrently with the long
+	// poll for the result, and passes it to OnProgress. Progress reporting is best-effort: failures to get the operation
+	// information are ignored and the callback is not invoked for operations that complete synchronously or before the
+	// first interval elapses. The callback is called from a separate goroutine but never concurrently with itself, and
+	// not after ExecuteOperation returns.
+	OnProgress func(OperationInfo)
+	// Interval between progress reports, see OnProgress. Defaults to 5 seconds.
+	ProgressInterval time.Duration
 }
 
 // ExecuteOperation is a helper for starting an operation and waiting for its completion.
@@ -339,6 +640,34 @@ type ExecuteOperationOptions struct {
 // ⚠️ If this method completes successfully, the returned response's body must be read in its entirety and closed to
 // free up the underlying connection.
 func (c *HTTPClient) ExecuteOperation(ctx context.Context, operation string, input any, options ExecuteOperationOptions) (*LazyValue, error) {
+	result, err := c.ExecuteOperationWithDetails(ctx, operation, input, options)
+	if err != nil {
+		return nil, err
+	}
+	return result.Value, nil
+}
+
+// ClientExecuteOperationResult is the return type of [HTTPClient.ExecuteOperationWithDetails].
+type ClientExecuteOperationResult struct {
+	// The operation's result, set if the operation succeeded.
+	//
+	// ⚠️ Must be consumed to free up the underlying connection.
+	Value *LazyValue
+	// Links attached by the handler to the start and get result responses, deduplicated by type and URL.
+	Links []Link
+	// The final state of the operation as observed by the caller.
+	State OperationState
+	// Whether the operation completed synchronously, in response to the start request.
+	Synchronous bool
+}
+
+// ExecuteOperationWithDetails is like [HTTPClient.ExecuteOperation] but also returns the links attached by the handler,
+// the final state of the operation, and whether the operation completed synchronously.
+//
+// In addition to successful results, a result is returned alongside an [UnsuccessfulOperationError] when the operation
+// completed unsuccessfully, and alongside [ErrOperationStillRunning] with the [OperationStateRunning] state when the
+// wait period exceeded. The result is nil for any other error.
+func (c *HTTPClient) ExecuteOperationWithDetails(ctx context.Context, operation string, input any, options ExecuteOperationOptions) (*ClientExecuteOperationResult, error) {
 	so := StartOperationOptions{
 		CallbackURL:    options.CallbackURL,
 		CallbackHeader: options.CallbackHeader,
@@ -348,10 +677,16 @@ func (c *HTTPClient) ExecuteOperation(ctx context.Context, operation string, inp
 	}
 	result, err := c.StartOperation(ctx, operation, input, so)
 	if err != nil {
-		return nil, err
+		var unsuccessfulOperationError *UnsuccessfulOperationError
+		if !errors.As(err, &unsuccessfulOperationError) {
+			return nil, err
+		}
+		value, err := c.mapOutcome(nil, unsuccessfulOperationError)
+		return executeOperationResult(value, nil, true, err), c.annotateError(operation, err)
 	}
 	if result.Successful != nil {
-		return result.Successful, nil
+		value, err := c.mapOutcome(result.Successful, nil)
+		return executeOperationResult(value, result.Links, true, err), c.annotateError(operation, err)
 	}
 	handle := result.Pending
 	gro := GetOperationResultOptions{
@@ -362,7 +697,99 @@ func (c *HTTPClient) ExecuteOperation(ctx context.Context, operation string, inp
 	} else {
 		gro.Wait = options.Wait
 	}
-	return handle.GetResult(ctx, gro)
+	if options.OnProgress != nil {
+		stop := reportProgress(ctx, handle, options)
+		defer stop()
+	}
+	value, links, _, err := handle.getResultWithOperationTimeout(ctx, gro, false)
+	return executeOperationResult(value, mergeLinks(result.Links, links), false, err), c.annotateError(operation, err)
+}
+
+// executeOperationResult builds the result of ExecuteOperationWithDetails from the terminal outcome of an operation,
+// returning nil for errors that don't indicate the operation's state.
+func executeOperationResult(value *LazyValue, links []Link, synchronous bool, err error) *ClientExecuteOperationResult {
+	result := &ClientExecuteOperationResult{Value: value, Links: links, State: OperationStateSucceeded, Synchronous: synchronous}
+	if err == nil {
+		return result
+	}
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	switch {
+	case errors.As(err, &unsuccessfulOperationError):
+		result.State = unsuccessfulOperationError.State
+	case errors.Is(err, ErrOperationStillRunning):
+		result.State = OperationStateRunning
+	default:
+		return nil
+	}
+	return result
+}
+
+// mapOutcome applies the OutcomeMapper option to the terminal outcome of an operation, given as either a successful
+// value or an [UnsuccessfulOperationError]. Other errors are returned as is.
+func (c *HTTPClient) mapOutcome(value *LazyValue, err error) (*LazyValue, error) {
+	if c.options.OutcomeMapper == nil {
+		return value, err
+	}
+	state := OperationStateSucceeded
+	var cause error
+	var retryable *bool
+	if err != nil {
+		var unsuccessfulOperationError *UnsuccessfulOperationError
+		if !errors.As(err, &unsuccessfulOperationError) {
+			return value, err
+		}
+		state, cause = unsuccessfulOperationError.State, unsuccessfulOperationError.Cause
+		retryable = unsuccessfulOperationError.Retryable
+	}
+	state, cause = c.options.OutcomeMapper(state, cause)
+	if state == OperationStateSucceeded {
+		if value == nil {
+			value = &LazyValue{
+				serializer: c.options.Serializer,
+				Reader:     &Reader{ReadCloser: http.NoBody, Header: Header{}},
+			}
+		}
+		return value, nil
+	}
+	if value != nil {
+		_, _ = io.Copy(io.Discard, value.Reader)
+		_ = value.Reader.Close()
+	}
+	return nil, &UnsuccessfulOperationError{State: state, Cause: cause, Retryable: retryable}
+}
+
+const defaultProgressInterval = 5 * time.Second
+
+// reportProgress periodically gets the info of the given operation and reports it to options.OnProgress until the
+// returned function is called. The returned function blocks until reporting has stopped.
+func reportProgress(ctx context.Context, handle *OperationHandle[*LazyValue], options ExecuteOperationOptions) func() {
+	interval := options.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			info, err := handle.GetInfo(ctx, GetOperationInfoOptions{Header: options.Header})
+			if err != nil || ctx.Err() != nil {
+				continue
+			}
+			options.OnProgress(*info)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
 // NewHandle gets a handle to an asynchronous operation by name and ID.
@@ -386,17 +813,116 @@ func (c *HTTPClient) NewHandle(operation string, operationID string) (*Operation
 	}, nil
 }
 
+// addDeadlineToHTTPHeader sets the deadline headers for the given context on the given header.
+func (c *HTTPClient) addDeadlineToHTTPHeader(ctx context.Context, httpHeader http.Header) {
+	addContextTimeoutToHTTPHeader(ctx, httpHeader)
+	if deadline, ok := ctx.Deadline(); ok && c.options.SendAbsoluteDeadline {
+		httpHeader.Set(headerDeadline, deadline.UTC().Format(time.RFC3339Nano))
+	}
+}
+
+// newRequest creates a request with the given context, installing the client's ClientTrace if set.
+func (c *HTTPClient) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	if c.options.ClientTrace != nil {
+		ctx = httptrace.WithClientTrace(ctx, c.options.ClientTrace)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	// Added first for SDK-provided and per call headers to take precedence.
+	addNexusHeaderToHTTPHeader(c.options.DefaultHeader, request.Header)
+	return request, nil
+}
+
+// sendRequest sends a request with the configured HTTPCaller. Errors caused by the request's context are wrapped with
+// ErrRequestCanceled, including errors reading the response body, which is closed on such errors.
+func (c *HTTPClient) sendRequest(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return nil, requestCanceledError(ctx, err)
+	}
+	response.Body = &contextBody{response.Body, ctx}
+	return response, nil
+}
+
+// requestCanceledError wraps err with ErrRequestCanceled and the context's error if the context is done.
+func requestCanceledError(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if ctxErr == nil || errors.Is(err, ErrRequestCanceled) {
+		return err
+	}
+	if !errors.Is(err, ctxErr) {
+		err = errors.Join(ctxErr, err)
+	}
+	return fmt.Errorf("%w: %w", ErrRequestCanceled, err)
+}
+
+// contextBody closes a response body once reading it fails due to its request's context being done.
+type contextBody struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (b *contextBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.ctx.Err() != nil {
+		_ = b.ReadCloser.Close()
+		err = requestCanceledError(b.ctx, err)
+	}
+	return n, err
+}
+
 // readAndReplaceBody reads the response body in its entirety and closes it, and then replaces the original response
 // body with an in-memory buffer.
 // The body is replaced even when there was an error reading the entire body.
-func readAndReplaceBody(response *http.Response) ([]byte, error) {
+// If the body exceeds MaxResponseBodySize, it is truncated and an [UnexpectedResponseError] is returned.
+func (c *HTTPClient) readAndReplaceBody(response *http.Response) ([]byte, error) {
 	responseBody := response.Body
-	body, err := io.ReadAll(responseBody)
+	maxSize := c.options.MaxResponseBodySize
+	var body []byte
+	var err error
+	if maxSize > 0 {
+		body, err = io.ReadAll(io.LimitReader(responseBody, maxSize+1))
+	} else {
+		body, err = io.ReadAll(responseBody)
+	}
 	responseBody.Close()
+	if maxSize > 0 && int64(len(body)) > maxSize {
+		body = body[:maxSize]
+		err = newUnexpectedResponseError(fmt.Sprintf("response body exceeds max size of %d bytes", maxSize), response, body)
+	}
 	response.Body = io.NopCloser(bytes.NewReader(body))
 	return body, err
 }
 
+// limitResponseBody limits the size of a response body that is read lazily, see MaxResponseBodySize.
+func (c *HTTPClient) limitResponseBody(body io.ReadCloser) io.ReadCloser {
+	if c.options.MaxResponseBodySize <= 0 {
+		return body
+	}
+	return &maxSizeResponseBody{body, c.options.MaxResponseBodySize, c.options.MaxResponseBodySize}
+}
+
+// maxSizeResponseBody fails reads once more than maxSize bytes have been read from the underlying body.
+type maxSizeResponseBody struct {
+	io.ReadCloser
+	maxSize   int64
+	remaining int64
+}
+
+func (b *maxSizeResponseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		return n, &UnexpectedResponseError{Message: fmt.Sprintf("response body exceeds max size of %d bytes", b.maxSize)}
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
 func operationInfoFromResponse(response *http.Response, body []byte) (*OperationInfo, error) {
 	if !isMediaTypeJSON(response.Header.Get("Content-Type")) {
 		return nil, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get("Content-Type")), response, body)
@@ -432,37 +958,81 @@ func (c *HTTPClient) failureErrorFromResponseOrDefault(response *http.Response,
 }
 
 func (c *HTTPClient) bestEffortHandlerErrorFromResponse(response *http.Response, body []byte) error {
-	switch response.StatusCode {
+	err := c.handlerErrorFromResponseStatus(response, body)
+	if handlerError, ok := err.(*HandlerError); ok {
+		if failure, err := c.failureFromResponse(response, body); err == nil {
+			handlerError.RetryGuidance = getRetryGuidanceFromFailureMetadata(failure.Metadata)
+		}
+	}
+	return err
+}
+
+// Default failure messages of handler errors, used when a response doesn't carry a failure.
+var handlerErrorTypeMessages = map[HandlerErrorType]string{
+	HandlerErrorTypeBadRequest:        "bad request",
+	HandlerErrorTypeUnauthenticated:   "unauthenticated",
+	HandlerErrorTypeUnauthorized:      "unauthorized",
+	HandlerErrorTypeNotFound:          "not found",
+	HandlerErrorTypeResourceExhausted: "resource exhausted",
+	HandlerErrorTypeInternal:          "internal error",
+	HandlerErrorTypeNotImplemented:    "not implemented",
+	HandlerErrorTypeUnavailable:       "unavailable",
+	HandlerErrorTypeUpstreamTimeout:   "upstream timeout",
+}
+
+// DefaultHandlerErrorTypeFromStatusCode maps the HTTP status code of a response to the [HandlerErrorType] it carries,
+// as defined by the Nexus HTTP protocol. Returns false for status codes that don't indicate a handler error.
+//
+// See HTTPClientOptions.HandlerErrorTypeFromStatusCode for overriding the mapping.
+func DefaultHandlerErrorTypeFromStatusCode(statusCode int) (HandlerErrorType, bool) {
+	switch statusCode {
 	case http.StatusBadRequest:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "bad request")
-		return &HandlerError{Type: HandlerErrorTypeBadRequest, Cause: failureErr}
+		return HandlerErrorTypeBadRequest, true
 	case http.StatusUnauthorized:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "unauthenticated")
-		return &HandlerError{Type: HandlerErrorTypeUnauthenticated, Cause: failureErr}
+		return HandlerErrorTypeUnauthenticated, true
 	case http.StatusForbidden:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "unauthorized")
-		return &HandlerError{Type: HandlerErrorTypeUnauthorized, Cause: failureErr}
+		return HandlerErrorTypeUnauthorized, true
 	case http.StatusNotFound:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "not found")
-		return &HandlerError{Type: HandlerErrorTypeNotFound, Cause: failureErr}
+		return HandlerErrorTypeNotFound, true
 	case http.StatusTooManyRequests:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "resource exhausted")
-		return &HandlerError{Type: HandlerErrorTypeResourceExhausted, Cause: failureErr}
+		return HandlerErrorTypeResourceExhausted, true
 	case http.StatusInternalServerError:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "internal error")
-		return &HandlerError{Type: HandlerErrorTypeInternal, Cause: failureErr}
+		return HandlerErrorTypeInternal, true
 	case http.StatusNotImplemented:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "not implemented")
-		return &HandlerError{Type: HandlerErrorTypeNotImplemented, Cause: failureErr}
+		return HandlerErrorTypeNotImplemented, true
 	case http.StatusServiceUnavailable:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "unavailable")
-		return &HandlerError{Type: HandlerErrorTypeUnavailable, Cause: failureErr}
+		return HandlerErrorTypeUnavailable, true
 	case StatusUpstreamTimeout:
-		failureErr := c.failureErrorFromResponseOrDefault(response, body, "upstream timeout")
-		return &HandlerError{Type: HandlerErrorTypeUpstreamTimeout, Cause: failureErr}
+		return HandlerErrorTypeUpstreamTimeout, true
 	default:
+		return "", false
+	}
+}
+
+func (c *HTTPClient) handlerErrorFromResponseStatus(response *http.Response, body []byte) error {
+	errorType, ok := c.options.HandlerErrorTypeFromStatusCode(response.StatusCode)
+	if !ok {
 		return newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
 	}
+	message, ok := handlerErrorTypeMessages[errorType]
+	if !ok {
+		message = strings.ToLower(string(errorType))
+	}
+	handlerError := &HandlerError{Type: errorType, Cause: c.failureErrorFromResponseOrDefault(response, body, message)}
+	if errorType == HandlerErrorTypeResourceExhausted {
+		handlerError.Quota = getQuotaFromHeader(response.Header)
+	}
+	return handlerError
+}
+
+// getRetryableFromHeader returns the retryable hint of an unsuccessful operation response, or nil if not set or
+// invalid, since the hint is advisory.
+func getRetryableFromHeader(header http.Header) *bool {
+	retryable, err := strconv.ParseBool(header.Get(headerOperationRetryable))
+	if err != nil {
+		return nil
+	}
+	return &retryable
 }
 
 func getUnsuccessfulStateFromHeader(response *http.Response, body []byte) (OperationState, error) {
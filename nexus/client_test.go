@@ -1,7 +1,11 @@
 package nexus
 
 import (
+	"context"
+	"io"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -29,3 +33,75 @@ func TestNewClient(t *testing.T) {
 	_, err = NewHTTPClient(HTTPClientOptions{BaseURL: "https://example.com", Service: "valid"})
 	require.NoError(t, err)
 }
+
+func TestUnexpectedResponseError_Accessors(t *testing.T) {
+	client, err := NewHTTPClient(HTTPClientOptions{
+		BaseURL: "http://localhost",
+		Service: "svc",
+		HTTPCaller: func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusTeapot,
+				Status:     "418 I'm a teapot",
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("short and stout")),
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.StartOperation(context.Background(), "op", nil, StartOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	statusCode, ok := unexpectedResponseError.StatusCode()
+	require.True(t, ok)
+	require.Equal(t, http.StatusTeapot, statusCode)
+	require.Equal(t, []byte("short and stout"), unexpectedResponseError.ResponseBody())
+
+	_, ok = (&UnexpectedResponseError{Message: "other"}).StatusCode()
+	require.False(t, ok)
+}
+
+func TestMaxResponseBodySize(t *testing.T) {
+	var statusCode int
+	body := strings.Repeat("a", 100)
+	client, err := NewHTTPClient(HTTPClientOptions{
+		BaseURL: "http://localhost",
+		Service: "svc",
+		HTTPCaller: func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: statusCode,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`"` + body + `"`)),
+			}, nil
+		},
+		MaxResponseBodySize: 50,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	statusCode = http.StatusBadRequest
+	_, err = client.StartOperation(ctx, "op", nil, StartOperationOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.ErrorContains(t, err, "response body exceeds max size of 50 bytes")
+	require.Len(t, unexpectedResponseError.ResponseBody(), 50)
+
+	handle, err := client.NewHandle("op", "id")
+	require.NoError(t, err)
+	statusCode = http.StatusOK
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorAs(t, err, &unexpectedResponseError)
+
+	result, err := client.StartOperation(ctx, "op", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	var out string
+	err = result.Successful.Consume(&out)
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.ErrorContains(t, err, "response body exceeds max size of 50 bytes")
+
+	client.options.MaxResponseBodySize = 0
+	result, err = client.StartOperation(ctx, "op", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.NoError(t, result.Successful.Consume(&out))
+	require.Equal(t, body, out)
+}
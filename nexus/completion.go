@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"maps"
@@ -48,6 +49,8 @@ type OperationCompletionSuccessful struct {
 	StartTime time.Time
 	// Links are used to link back to the operation when a completion callback is received before a started response.
 	Links []Link
+	// Optional signer to sign the completion request with, e.g. [HMACCompletionSigner].
+	Signer CompletionSigner
 }
 
 // OperationCompletionSuccessfulOptions are options for [NewOperationCompletionSuccessful].
@@ -61,6 +64,8 @@ type OperationCompletionSuccessfulOptions struct {
 	StartTime time.Time
 	// Links are used to link back to the operation when a completion callback is received before a started response.
 	Links []Link
+	// Optional signer to sign the completion request with, e.g. [HMACCompletionSigner].
+	Signer CompletionSigner
 }
 
 // NewOperationCompletionSuccessful constructs an [OperationCompletionSuccessful] from a given result.
@@ -97,6 +102,7 @@ func NewOperationCompletionSuccessful(result any, options OperationCompletionSuc
 		OperationID: options.OperationID,
 		StartTime:   options.StartTime,
 		Links:       options.Links,
+		Signer:      options.Signer,
 	}, nil
 }
 
@@ -124,6 +130,9 @@ func (c *OperationCompletionSuccessful) applyToHTTPRequest(request *http.Request
 	}
 
 	request.Body = c.Reader.ReadCloser
+	if c.Signer != nil {
+		return c.Signer.SignCompletion(request)
+	}
 	return nil
 }
 
@@ -143,6 +152,8 @@ type OperationCompletionUnsuccessful struct {
 	Links []Link
 	// Failure object to send with the completion.
 	Failure Failure
+	// Optional signer to sign the completion request with, e.g. [HMACCompletionSigner].
+	Signer CompletionSigner
 }
 
 // OperationCompletionUnsuccessfulOptions are options for [NewOperationCompletionUnsuccessful].
@@ -156,6 +167,8 @@ type OperationCompletionUnsuccessfulOptions struct {
 	StartTime time.Time
 	// Links are used to link back to the operation when a completion callback is received before a started response.
 	Links []Link
+	// Optional signer to sign the completion request with, e.g. [HMACCompletionSigner].
+	Signer CompletionSigner
 }
 
 // NewOperationCompletionUnsuccessful constructs an [OperationCompletionUnsuccessful] from a given error.
@@ -171,6 +184,7 @@ func NewOperationCompletionUnsuccessful(error *UnsuccessfulOperationError, optio
 		OperationID: options.OperationID,
 		StartTime:   options.StartTime,
 		Links:       options.Links,
+		Signer:      options.Signer,
 	}, nil
 }
 
@@ -201,6 +215,9 @@ func (c *OperationCompletionUnsuccessful) applyToHTTPRequest(request *http.Reque
 	}
 
 	request.Body = io.NopCloser(bytes.NewReader(b))
+	if c.Signer != nil {
+		return c.Signer.SignCompletion(request)
+	}
 	return nil
 }
 
@@ -228,6 +245,65 @@ type CompletionHandler interface {
 	CompleteOperation(context.Context, *CompletionRequest) error
 }
 
+// MultiCompletionHandlerOptions are options for [NewMultiCompletionHandler].
+type MultiCompletionHandlerOptions struct {
+	// Handlers to deliver each completion to, in order.
+	Handlers []CompletionHandler
+	// By default all handlers are invoked and their errors are joined. If set, handlers following the first one that
+	// returns an error are skipped and that error is returned as is.
+	StopOnFirstError bool
+}
+
+// NewMultiCompletionHandler creates a [CompletionHandler] that delivers each completion to multiple handlers, e.g. to
+// separate persistence from metrics. A completion is considered successful only if all handlers succeed.
+//
+// Since a successful completion's result can only be read once, the multi handler reads it into memory and gives each
+// handler a copy of the [CompletionRequest] with its own Result that reads from that buffer. Consider setting
+// [CompletionHandlerOptions.MaxRequestBodySize] to bound the memory used.
+func NewMultiCompletionHandler(options MultiCompletionHandlerOptions) CompletionHandler {
+	return &multiCompletionHandler{options: options}
+}
+
+type multiCompletionHandler struct {
+	options MultiCompletionHandlerOptions
+}
+
+// CompleteOperation implements CompletionHandler.
+func (h *multiCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	var result []byte
+	if completion.Result != nil {
+		var err error
+		result, err = io.ReadAll(completion.Result.Reader)
+		completion.Result.Reader.Close()
+		if err != nil {
+			return HandlerErrorf(HandlerErrorTypeBadRequest, "failed to read result from request body: %v", err)
+		}
+	}
+	var errs []error
+	for _, handler := range h.options.Handlers {
+		c := *completion
+		if completion.Result != nil {
+			c.Result = &LazyValue{
+				serializer: completion.Result.serializer,
+				Reader: &Reader{
+					io.NopCloser(bytes.NewReader(result)),
+					maps.Clone(completion.Result.Reader.Header),
+				},
+			}
+		}
+		if err := handler.CompleteOperation(ctx, &c); err != nil {
+			if h.options.StopOnFirstError {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
 // CompletionHandlerOptions are options for [NewCompletionHTTPHandler].
 type CompletionHandlerOptions struct {
 	// Handler for completion requests.
@@ -241,8 +317,29 @@ type CompletionHandlerOptions struct {
 	// A [FailureConverter] to convert a [Failure] instance to and from an [error]. Defaults to
 	// [DefaultFailureConverter].
 	FailureConverter FailureConverter
+	// Maximum size in bytes of the failure body accepted in failed and canceled completion requests. Larger requests
+	// are rejected with a [HandlerErrorTypeBadRequest] error. Results of succeeded completion requests are streamed to
+	// the handler and not subject to this limit.
+	// Defaults to 1 MiB, set to a negative value to disable the limit.
+	MaxFailureBodySize int64
+	// Maximum size in bytes of the result body of succeeded completion requests. Requests that declare a larger body
+	// are rejected with a [HandlerErrorTypeBadRequest] error. Otherwise, consuming the result fails once the limit is
+	// exceeded.
+	// Unlimited by default.
+	MaxRequestBodySize int64
+	// If set, completion requests that do not identify the operation via the operation ID header are rejected with a
+	// [HandlerErrorTypeBadRequest] error. Useful to catch misconfigured callers in deployments that rely on the
+	// operation ID for correlation.
+	RequireOperationID bool
+	// Optional verifier to authenticate completion requests with before handling them, e.g. [HMACCompletionSigner].
+	// Requests that fail verification are rejected with a [HandlerErrorTypeUnauthenticated] error unless the verifier
+	// returns a [HandlerError]. The request body read by the verifier is subject to MaxRequestBodySize, for all
+	// completion states.
+	Verifier CompletionVerifier
 }
 
+const defaultMaxFailureBodySize = 1 << 20
+
 type completionHTTPHandler struct {
 	baseHTTPHandler
 	options CompletionHandlerOptions
@@ -255,6 +352,24 @@ func (h *completionHTTPHandler) ServeHTTP(writer http.ResponseWriter, request *h
 		OperationID: request.Header.Get(HeaderOperationID),
 		HTTPRequest: request,
 	}
+	if h.options.Verifier != nil {
+		if h.options.MaxRequestBodySize > 0 {
+			request.Body = http.MaxBytesReader(writer, request.Body, h.options.MaxRequestBodySize)
+		}
+		if err := h.options.Verifier.VerifyCompletion(request); err != nil {
+			var handlerErr *HandlerError
+			if !errors.As(err, &handlerErr) {
+				h.logger.Warn("completion request verification failed", "error", err)
+				err = HandlerErrorf(HandlerErrorTypeUnauthenticated, "unauthenticated")
+			}
+			h.writeFailure(writer, err)
+			return
+		}
+	}
+	if h.options.RequireOperationID && completion.OperationID == "" {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "missing %q header", HeaderOperationID))
+		return
+	}
 	if startTimeHeader := request.Header.Get(headerOperationStartTime); startTimeHeader != "" {
 		var parseTimeErr error
 		if completion.StartTime, parseTimeErr = http.ParseTime(startTimeHeader); parseTimeErr != nil {
@@ -274,21 +389,37 @@ func (h *completionHTTPHandler) ServeHTTP(writer http.ResponseWriter, request *h
 			return
 		}
 		var failure Failure
-		b, err := io.ReadAll(request.Body)
+		body := io.Reader(request.Body)
+		if h.options.MaxFailureBodySize > 0 {
+			body = io.LimitReader(request.Body, h.options.MaxFailureBodySize+1)
+		}
+		b, err := io.ReadAll(body)
 		if err != nil {
 			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to read Failure from request body"))
 			return
 		}
+		if h.options.MaxFailureBodySize > 0 && int64(len(b)) > h.options.MaxFailureBodySize {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failure body exceeds max size of %d bytes", h.options.MaxFailureBodySize))
+			return
+		}
 		if err := json.Unmarshal(b, &failure); err != nil {
 			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to read Failure from request body"))
 			return
 		}
 		completion.Error = h.failureConverter.FailureToError(failure)
 	case OperationStateSucceeded:
+		body := request.Body
+		if h.options.MaxRequestBodySize > 0 {
+			if request.ContentLength > h.options.MaxRequestBodySize {
+				h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "request body exceeds max size of %d bytes", h.options.MaxRequestBodySize))
+				return
+			}
+			body = http.MaxBytesReader(writer, body, h.options.MaxRequestBodySize)
+		}
 		completion.Result = &LazyValue{
 			serializer: h.options.Serializer,
 			Reader: &Reader{
-				request.Body,
+				body,
 				prefixStrippedHTTPHeaderToNexusHeader(request.Header, "content-"),
 			},
 		}
@@ -312,6 +443,9 @@ func NewCompletionHTTPHandler(options CompletionHandlerOptions) http.Handler {
 	if options.FailureConverter == nil {
 		options.FailureConverter = defaultFailureConverter
 	}
+	if options.MaxFailureBodySize == 0 {
+		options.MaxFailureBodySize = defaultMaxFailureBodySize
+	}
 	return &completionHTTPHandler{
 		options: options,
 		baseHTTPHandler: baseHTTPHandler{
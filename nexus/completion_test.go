@@ -5,7 +5,10 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -229,3 +232,251 @@ func TestBadRequestCompletion(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, http.StatusBadRequest, response.StatusCode)
 }
+
+func TestFailureCompletion_MaxFailureBodySize(t *testing.T) {
+	handler := NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler: &failureExpectingCompletionHandler{
+			errorChecker: func(err error) error { return nil },
+		},
+		MaxFailureBodySize: 64,
+	})
+
+	completion, err := NewOperationCompletionUnsuccessful(NewCanceledOperationError(errors.New(strings.Repeat("x", 64))), OperationCompletionUnsuccessfulOptions{})
+	require.NoError(t, err)
+	request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+	require.NoError(t, err)
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.Contains(t, writer.Body.String(), "failure body exceeds max size of 64 bytes")
+}
+
+func TestCompletion_RequireOperationID(t *testing.T) {
+	handler := NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:            &failingCompletionHandler{},
+		RequireOperationID: true,
+	})
+
+	completion, err := NewOperationCompletionSuccessful([]byte("success"), OperationCompletionSuccessfulOptions{})
+	require.NoError(t, err)
+	request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+	require.NoError(t, err)
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.Contains(t, writer.Body.String(), `missing \"nexus-operation-id\" header`)
+}
+
+type resultConsumingCompletionHandler struct{}
+
+func (h *resultConsumingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	var result []byte
+	if err := completion.Result.Consume(&result); err != nil {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid result: %v", err)
+	}
+	return nil
+}
+
+func TestSuccessfulCompletion_MaxRequestBodySize(t *testing.T) {
+	handler := NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:            &resultConsumingCompletionHandler{},
+		MaxRequestBodySize: 4,
+	})
+
+	completion, err := NewOperationCompletionSuccessful([]byte("success"), OperationCompletionSuccessfulOptions{})
+	require.NoError(t, err)
+	request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+	require.NoError(t, err)
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.Contains(t, writer.Body.String(), "request body too large")
+
+	request, err = NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+	require.NoError(t, err)
+	request.ContentLength = int64(len("success"))
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.Contains(t, writer.Body.String(), "request body exceeds max size of 4 bytes")
+
+	handler = NewCompletionHTTPHandler(CompletionHandlerOptions{Handler: &resultConsumingCompletionHandler{}})
+	request, err = NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+	require.NoError(t, err)
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+}
+
+type recordingCompletionHandler struct {
+	results []string
+	err     error
+}
+
+func (h *recordingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	var result string
+	if err := completion.Result.Consume(&result); err != nil {
+		return err
+	}
+	h.results = append(h.results, result)
+	return h.err
+}
+
+func TestMultiCompletionHandler(t *testing.T) {
+	send := func(handler CompletionHandler) *httptest.ResponseRecorder {
+		completion, err := NewOperationCompletionSuccessful("success", OperationCompletionSuccessfulOptions{})
+		require.NoError(t, err)
+		request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+		require.NoError(t, err)
+		writer := httptest.NewRecorder()
+		NewCompletionHTTPHandler(CompletionHandlerOptions{Handler: handler}).ServeHTTP(writer, request)
+		return writer
+	}
+
+	a, b := &recordingCompletionHandler{}, &recordingCompletionHandler{}
+	writer := send(NewMultiCompletionHandler(MultiCompletionHandlerOptions{Handlers: []CompletionHandler{a, b}}))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, []string{"success"}, a.results)
+	require.Equal(t, []string{"success"}, b.results)
+
+	t.Run("AllMustSucceed", func(t *testing.T) {
+		a := &recordingCompletionHandler{err: HandlerErrorf(HandlerErrorTypeUnavailable, "a failed")}
+		b := &recordingCompletionHandler{}
+		writer := send(NewMultiCompletionHandler(MultiCompletionHandlerOptions{Handlers: []CompletionHandler{a, b}}))
+		require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+		require.Contains(t, writer.Body.String(), "a failed")
+		require.Equal(t, []string{"success"}, b.results)
+	})
+
+	t.Run("StopOnFirstError", func(t *testing.T) {
+		a := &recordingCompletionHandler{err: HandlerErrorf(HandlerErrorTypeUnavailable, "a failed")}
+		b := &recordingCompletionHandler{}
+		writer := send(NewMultiCompletionHandler(MultiCompletionHandlerOptions{
+			Handlers:         []CompletionHandler{a, b},
+			StopOnFirstError: true,
+		}))
+		require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+		require.Empty(t, b.results)
+	})
+}
+
+type stateCheckingCompletionHandler struct{}
+
+func (h *stateCheckingCompletionHandler) CompleteOperation(ctx context.Context, completion *CompletionRequest) error {
+	if completion.State == OperationStateSucceeded {
+		var result []byte
+		if err := completion.Result.Consume(&result); err != nil || string(result) != "success" {
+			return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid result: %q, %v", result, err)
+		}
+	} else if completion.Error == nil || completion.Error.Error() != "failed" {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "invalid error: %v", completion.Error)
+	}
+	return nil
+}
+
+func TestCompletionSignature(t *testing.T) {
+	signer := &HMACCompletionSigner{Key: []byte("secret")}
+	handler := NewCompletionHTTPHandler(CompletionHandlerOptions{
+		Handler:  &stateCheckingCompletionHandler{},
+		Verifier: signer,
+	})
+	newRequest := func(t *testing.T, signer CompletionSigner) *http.Request {
+		completion, err := NewOperationCompletionSuccessful([]byte("success"), OperationCompletionSuccessfulOptions{
+			OperationID: "test-operation-id",
+			Signer:      signer,
+		})
+		require.NoError(t, err)
+		request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+		require.NoError(t, err)
+		return request
+	}
+	send := func(request *http.Request) *httptest.ResponseRecorder {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		return writer
+	}
+
+	request := newRequest(t, signer)
+	require.Contains(t, request.Header.Get(HeaderCompletionSignature), "sha256=")
+	require.Equal(t, http.StatusOK, send(request).Code)
+
+	t.Run("Unsuccessful", func(t *testing.T) {
+		completion, err := NewOperationCompletionUnsuccessful(NewFailedOperationError(errors.New("failed")), OperationCompletionUnsuccessfulOptions{
+			Signer: signer,
+		})
+		require.NoError(t, err)
+		request, err := NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, send(request).Code)
+	})
+
+	t.Run("TamperedBody", func(t *testing.T) {
+		request := newRequest(t, signer)
+		request.Body = io.NopCloser(strings.NewReader("tampered"))
+		writer := send(request)
+		require.Equal(t, http.StatusUnauthorized, writer.Code)
+		require.Contains(t, writer.Body.String(), "invalid completion signature")
+	})
+
+	t.Run("TamperedOperationID", func(t *testing.T) {
+		request := newRequest(t, signer)
+		request.Header.Set(HeaderOperationID, "other-operation-id")
+		require.Equal(t, http.StatusUnauthorized, send(request).Code)
+	})
+
+	t.Run("TamperedContentType", func(t *testing.T) {
+		request := newRequest(t, signer)
+		request.Header.Set("Content-Type", "text/plain")
+		require.Equal(t, http.StatusUnauthorized, send(request).Code)
+	})
+
+	t.Run("TamperedLinks", func(t *testing.T) {
+		request := newRequest(t, signer)
+		request.Header.Add(headerLink, `<https://example.com/other>; type="url"`)
+		require.Equal(t, http.StatusUnauthorized, send(request).Code)
+	})
+
+	t.Run("TamperedTimestamp", func(t *testing.T) {
+		request := newRequest(t, signer)
+		request.Header.Set(HeaderCompletionTimestamp, strconv.FormatInt(time.Now().Unix()+1, 10))
+		require.Equal(t, http.StatusUnauthorized, send(request).Code)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		past := &HMACCompletionSigner{Key: signer.Key, now: func() time.Time { return time.Now().Add(-10 * time.Minute) }}
+		writer := send(newRequest(t, past))
+		require.Equal(t, http.StatusUnauthorized, writer.Code)
+		require.Contains(t, writer.Body.String(), "expired")
+
+		future := &HMACCompletionSigner{Key: signer.Key, now: func() time.Time { return time.Now().Add(10 * time.Minute) }}
+		require.Equal(t, http.StatusUnauthorized, send(newRequest(t, future)).Code)
+
+		tolerant := NewCompletionHTTPHandler(CompletionHandlerOptions{
+			Handler:  &stateCheckingCompletionHandler{},
+			Verifier: &HMACCompletionSigner{Key: signer.Key, Tolerance: time.Hour},
+		})
+		writer = httptest.NewRecorder()
+		tolerant.ServeHTTP(writer, newRequest(t, past))
+		require.Equal(t, http.StatusOK, writer.Code)
+	})
+
+	t.Run("EmptyKey", func(t *testing.T) {
+		completion, err := NewOperationCompletionSuccessful([]byte("success"), OperationCompletionSuccessfulOptions{
+			Signer: &HMACCompletionSigner{},
+		})
+		require.NoError(t, err)
+		_, err = NewCompletionHTTPRequest(context.Background(), "http://localhost/callback", completion)
+		require.ErrorIs(t, err, errEmptyHMACKey)
+		require.ErrorIs(t, (&HMACCompletionSigner{}).VerifyCompletion(newRequest(t, signer)), errEmptyHMACKey)
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, send(newRequest(t, &HMACCompletionSigner{Key: []byte("other")})).Code)
+	})
+
+	t.Run("MissingSignature", func(t *testing.T) {
+		writer := send(newRequest(t, nil))
+		require.Equal(t, http.StatusUnauthorized, writer.Code)
+		require.Contains(t, writer.Body.String(), "missing")
+	})
+}
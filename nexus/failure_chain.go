@@ -0,0 +1,145 @@
+package nexus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// failureTypeMetadataKey is the Failure.Metadata key holding the name of a registered error type, see
+// [ChainFailureConverter].
+const failureTypeMetadataKey = "type"
+
+// ChainFailureConverter is a [FailureConverter] that preserves Go error chains across service boundaries.
+//
+// When converting an error to a [Failure], the chain of wrapped errors is converted to nested failures linked via
+// Failure.Cause. Since a failure has a single cause, errors that wrap multiple errors, such as those created with
+// [errors.Join], have the failures of the wrapped errors chained one after another. Errors of registered types are identified by a type name
+// stored in the "type" Failure.Metadata key, and their JSON encoding is stored in Failure.Details. [FailureError]
+// instances are converted to their underlying Failure.
+//
+// When converting a [Failure] to an error, failures with a registered type name are reconstructed with the registered
+// decoder and other failures are converted to [FailureError]. The reconstructed cause is attached to the resulting
+// error, so that [errors.Is] and [errors.As] match any error in the original chain of registered types.
+//
+// Register error types before using the converter. Register must not be called concurrently with conversions.
+type ChainFailureConverter struct {
+	names    map[reflect.Type]string
+	decoders map[string]func(Failure) error
+}
+
+// NewChainFailureConverter creates a [ChainFailureConverter] with no registered error types.
+func NewChainFailureConverter() *ChainFailureConverter {
+	return &ChainFailureConverter{
+		names:    make(map[reflect.Type]string),
+		decoders: make(map[string]func(Failure) error),
+	}
+}
+
+// Register registers the dynamic type of example under the given name, with a decoder that reconstructs an error of
+// that type from a [Failure], typically by unmarshaling its Details. The decoder may return nil to fall back to a
+// [FailureError].
+//
+//	converter.Register("my.NotFound", &NotFoundError{}, func(f nexus.Failure) error {
+//		var err NotFoundError
+//		if err := json.Unmarshal(f.Details, &err); err != nil {
+//			return nil
+//		}
+//		return &err
+//	})
+//
+// Returns an error if the name is empty or if the name or type are already registered.
+func (c *ChainFailureConverter) Register(name string, example error, decode func(Failure) error) error {
+	if name == "" {
+		return errors.New("tried to register an error type with no name")
+	}
+	if example == nil || decode == nil {
+		return fmt.Errorf("invalid registration for error type %q: example and decode are required", name)
+	}
+	t := reflect.TypeOf(example)
+	if existing, ok := c.names[t]; ok {
+		return fmt.Errorf("error type %s already registered as %q", t, existing)
+	}
+	if _, ok := c.decoders[name]; ok {
+		return fmt.Errorf("error type name %q already registered", name)
+	}
+	c.names[t] = name
+	c.decoders[name] = decode
+	return nil
+}
+
+// ErrorToFailure implements FailureConverter.
+func (c *ChainFailureConverter) ErrorToFailure(err error) Failure {
+	if err == nil {
+		return Failure{}
+	}
+	switch e := err.(type) {
+	case *FailureError:
+		return e.Failure
+	case *causedError:
+		// Convert reconstructed errors back to the failures they were reconstructed from.
+		failure := c.ErrorToFailure(e.error)
+		cause := c.ErrorToFailure(e.cause)
+		failure.Cause = &cause
+		return failure
+	}
+	failure := Failure{Message: err.Error()}
+	if name, ok := c.names[reflect.TypeOf(err)]; ok {
+		failure.Metadata = map[string]string{failureTypeMetadataKey: name}
+		// Best effort, errors that can't be marshaled are identified by their type name only.
+		if details, marshalErr := json.Marshal(err); marshalErr == nil {
+			failure.Details = details
+		}
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := e.Unwrap(); next != nil {
+			cause := c.ErrorToFailure(next)
+			failure.Cause = &cause
+		}
+	case interface{ Unwrap() []error }:
+		for _, next := range e.Unwrap() {
+			if next != nil {
+				failure = withCause(failure, c.ErrorToFailure(next))
+			}
+		}
+	}
+	return failure
+}
+
+// withCause returns a copy of failure with cause attached at the end of its chain of causes. Failures in the chain are
+// copied rather than modified since they may be shared with a [FailureError].
+func withCause(failure Failure, cause Failure) Failure {
+	if failure.Cause != nil {
+		cause = withCause(*failure.Cause, cause)
+	}
+	failure.Cause = &cause
+	return failure
+}
+
+// FailureToError implements FailureConverter.
+func (c *ChainFailureConverter) FailureToError(failure Failure) error {
+	var err error
+	if decode, ok := c.decoders[failure.Metadata[failureTypeMetadataKey]]; ok {
+		err = decode(failure)
+	}
+	if err == nil {
+		err = &FailureError{failure}
+	}
+	if failure.Cause == nil {
+		return err
+	}
+	return &causedError{err, c.FailureToError(*failure.Cause)}
+}
+
+// causedError attaches a cause to a reconstructed error, keeping the reconstructed error's message.
+type causedError struct {
+	error
+	cause error
+}
+
+// Unwrap returns the reconstructed error and its cause for use with utilities in the errors package.
+func (e *causedError) Unwrap() []error {
+	return []error{e.error, e.cause}
+}
@@ -0,0 +1,122 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type quotaError struct {
+	Resource string `json:"resource"`
+}
+
+func (e *quotaError) Error() string {
+	return "quota exceeded for " + e.Resource
+}
+
+func newTestChainFailureConverter(t *testing.T) *ChainFailureConverter {
+	c := NewChainFailureConverter()
+	require.NoError(t, c.Register("test.Quota", &quotaError{}, func(f Failure) error {
+		var err quotaError
+		if json.Unmarshal(f.Details, &err) != nil {
+			return nil
+		}
+		return &err
+	}))
+	return c
+}
+
+func TestChainFailureConverter(t *testing.T) {
+	c := newTestChainFailureConverter(t)
+	require.ErrorContains(t, c.Register("test.Quota", errors.New("other"), func(Failure) error { return nil }), "already registered")
+	require.ErrorContains(t, c.Register("other", &quotaError{}, func(Failure) error { return nil }), "already registered")
+
+	err := fmt.Errorf("failed to reserve: %w", &quotaError{Resource: "cpu"})
+	failure := c.ErrorToFailure(err)
+	require.Equal(t, Failure{
+		Message: "failed to reserve: quota exceeded for cpu",
+		Cause: &Failure{
+			Message:  "quota exceeded for cpu",
+			Metadata: map[string]string{"type": "test.Quota"},
+			Details:  json.RawMessage(`{"resource":"cpu"}`),
+		},
+	}, failure)
+
+	b, err := json.Marshal(failure)
+	require.NoError(t, err)
+	var decoded Failure
+	require.NoError(t, json.Unmarshal(b, &decoded))
+
+	err = c.FailureToError(decoded)
+	require.Equal(t, "failed to reserve: quota exceeded for cpu", err.Error())
+	var quotaErr *quotaError
+	require.ErrorAs(t, err, &quotaErr)
+	require.Equal(t, "cpu", quotaErr.Resource)
+	var failureErr *FailureError
+	require.ErrorAs(t, err, &failureErr)
+	require.Equal(t, "failed to reserve: quota exceeded for cpu", failureErr.Failure.Message)
+
+	// Failure errors are converted to their underlying failure.
+	require.Equal(t, decoded, c.ErrorToFailure(&FailureError{decoded}))
+	require.Equal(t, Failure{}, c.ErrorToFailure(nil))
+}
+
+func TestChainFailureConverter_MultipleWrappedErrors(t *testing.T) {
+	c := newTestChainFailureConverter(t)
+	shared := &FailureError{Failure{Message: "shared", Cause: &Failure{Message: "shared cause"}}}
+	err := fmt.Errorf("failed to reserve: %w, %w", &quotaError{Resource: "cpu"}, errors.Join(shared, &quotaError{Resource: "disk"}))
+
+	// Round trip twice to verify reconstructed errors are converted back to the same failures.
+	failure := c.ErrorToFailure(err)
+	for i := 0; i < 2; i++ {
+		err = c.FailureToError(failure)
+		require.Equal(t, failure, c.ErrorToFailure(err))
+		require.Equal(t, "failed to reserve: quota exceeded for cpu, shared\nquota exceeded for disk", err.Error())
+		var resources []string
+		var walk func(error)
+		walk = func(e error) {
+			if quotaErr, ok := e.(*quotaError); ok {
+				resources = append(resources, quotaErr.Resource)
+			}
+			switch e := e.(type) {
+			case interface{ Unwrap() error }:
+				walk(e.Unwrap())
+			case interface{ Unwrap() []error }:
+				for _, next := range e.Unwrap() {
+					walk(next)
+				}
+			}
+		}
+		walk(err)
+		require.Equal(t, []string{"cpu", "disk"}, resources)
+		require.ErrorContains(t, err, "shared")
+		failure = c.ErrorToFailure(err)
+	}
+	// Failures shared with the original error are not modified.
+	require.Nil(t, shared.Failure.Cause.Cause)
+}
+
+type quotaFailingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *quotaFailingHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return nil, NewFailedOperationError(fmt.Errorf("failed to reserve: %w", &quotaError{Resource: "memory"}))
+}
+
+func TestChainFailureConverter_RoundTrip(t *testing.T) {
+	c := newTestChainFailureConverter(t)
+	ctx, client, teardown := setupCustom(t, &quotaFailingHandler{}, nil, c)
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	var quotaErr *quotaError
+	require.ErrorAs(t, unsuccessfulOperationError.Cause, &quotaErr)
+	require.Equal(t, "memory", quotaErr.Resource)
+}
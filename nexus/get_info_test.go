@@ -2,6 +2,9 @@ package nexus
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -130,3 +133,109 @@ func TestGetInfo_TimeoutNotPropagated(t *testing.T) {
 	_, err = handle.GetInfo(context.Background(), GetOperationInfoOptions{})
 	require.NoError(t, err)
 }
+
+type etagInfoHandler struct {
+	UnimplementedHandler
+	state             atomic.Value
+	ifNoneMatchValues []string
+}
+
+func (h *etagInfoHandler) GetOperationInfo(ctx context.Context, service, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	h.ifNoneMatchValues = append(h.ifNoneMatchValues, options.IfNoneMatch)
+	state := h.state.Load().(OperationState)
+	return &OperationInfo{ID: operationID, State: state, ETag: `"` + string(state) + `"`}, nil
+}
+
+func TestGetInfo_ETag(t *testing.T) {
+	handler := &etagInfoHandler{}
+	handler.state.Store(OperationStateRunning)
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	var statusCodes []int
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		response, err := http.DefaultClient.Do(r)
+		if err == nil {
+			statusCodes = append(statusCodes, response.StatusCode)
+		}
+		return response, err
+	}
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	expected := &OperationInfo{ID: "id", State: OperationStateRunning, ETag: `"running"`}
+	for i := 0; i < 2; i++ {
+		info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+		require.NoError(t, err)
+		require.Equal(t, expected, info)
+	}
+
+	handler.state.Store(OperationStateSucceeded)
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &OperationInfo{ID: "id", State: OperationStateSucceeded, ETag: `"succeeded"`}, info)
+
+	require.Equal(t, []string{"", `"running"`, `"running"`}, handler.ifNoneMatchValues)
+	require.Equal(t, []int{http.StatusOK, http.StatusNotModified, http.StatusOK}, statusCodes)
+}
+
+func TestETagMatches(t *testing.T) {
+	require.Equal(t, `"v1"`, quoteETag("v1"))
+	require.Equal(t, `"v1"`, quoteETag(`"v1"`))
+	require.Equal(t, `W/"v1"`, quoteETag(`W/"v1"`))
+	require.Equal(t, `"W/v1"`, quoteETag("W/v1"))
+
+	cases := []struct {
+		ifNoneMatch string
+		etag        string
+		matches     bool
+	}{
+		{`"v1"`, `"v1"`, true},
+		{`"v1"`, `"v2"`, false},
+		{`*`, `"v1"`, true},
+		{`"v0", "v1"`, `"v1"`, true},
+		{`"v0","v2" , "v3"`, `"v1"`, false},
+		{`W/"v1"`, `"v1"`, true},
+		{`"v1"`, `W/"v1"`, true},
+		{`"a,b", "v1"`, `"v1"`, true},
+		{`"a,b"`, `"a"`, false},
+		{`v1`, `"v1"`, false},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.matches, etagMatches(c.ifNoneMatch, c.etag), "If-None-Match: %s, ETag: %s", c.ifNoneMatch, c.etag)
+	}
+}
+
+type staticInfoHandler struct {
+	UnimplementedHandler
+	info *OperationInfo
+}
+
+func (h *staticInfoHandler) GetOperationInfo(ctx context.Context, service, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	return h.info, nil
+}
+
+func TestGetInfo_ConditionalRequest(t *testing.T) {
+	handler := &staticInfoHandler{info: &OperationInfo{ID: "id", State: OperationStateRunning, ETag: "v1"}}
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: handler})
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", "/svc/op/id", nil)
+		if ifNoneMatch != "" {
+			request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		recorder := httptest.NewRecorder()
+		httpHandler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	response := get("")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, `"v1"`, response.Header().Get("ETag"))
+	require.Equal(t, http.StatusNotModified, get(`"v0", W/"v1"`).Code)
+	require.Equal(t, http.StatusNotModified, get("*").Code)
+	require.Equal(t, http.StatusOK, get(`"v0"`).Code)
+
+	// Handlers returning no information fail rather than panic.
+	handler.info = nil
+	require.Equal(t, http.StatusInternalServerError, get("").Code)
+}
@@ -2,6 +2,13 @@ package nexus
 
 import (
 	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -194,3 +201,511 @@ func TestPeekResult_Canceled(t *testing.T) {
 	require.ErrorAs(t, err, &unsuccessfulOperationError)
 	require.Equal(t, OperationStateCanceled, unsuccessfulOperationError.State)
 }
+
+type deadlineReportingOperation struct {
+	UnimplementedOperation[NoValue, int64]
+	name string
+}
+
+func (h *deadlineReportingOperation) Name() string {
+	return h.name
+}
+
+func (h *deadlineReportingOperation) GetResult(ctx context.Context, id string, options GetOperationResultOptions) (int64, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, HandlerErrorf(HandlerErrorTypeBadRequest, "context deadline unset")
+	}
+	return time.Until(deadline).Milliseconds(), nil
+}
+
+func TestWaitResult_PerOperationTimeout(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(&deadlineReportingOperation{name: "default"}))
+	require.NoError(t, svc.RegisterWithOptions(&deadlineReportingOperation{name: "slow"}, OperationOptions{GetResultTimeout: 2 * time.Second}))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	var maxWaitHeader string
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		response, err := http.DefaultClient.Do(r)
+		if err == nil {
+			maxWaitHeader = response.Header.Get(headerMaxWait)
+		}
+		return response, err
+	}
+
+	handle, err := NewHandle(client, NewOperationReference[NoValue, int64]("default"), "id")
+	require.NoError(t, err)
+	remaining, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute})
+	require.NoError(t, err)
+	require.InDelta(t, getResultMaxTimeout.Milliseconds(), remaining, 100)
+	require.Equal(t, formatDuration(getResultMaxTimeout), maxWaitHeader)
+
+	handle, err = NewHandle(client, NewOperationReference[NoValue, int64]("slow"), "id")
+	require.NoError(t, err)
+	remaining, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute})
+	require.NoError(t, err)
+	require.InDelta(t, (2 * time.Second).Milliseconds(), remaining, 100)
+	require.Equal(t, "2000ms", maxWaitHeader)
+}
+
+func TestGetOperationResult_Typed(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(&deadlineReportingOperation{name: "op"}))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	link := Link{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/result"}, Type: "url"}
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		response, err := http.DefaultClient.Do(r)
+		if err == nil && response.StatusCode == http.StatusOK {
+			require.NoError(t, addLinksToHTTPHeader([]Link{link}, response.Header))
+		}
+		return response, err
+	}
+
+	ref := NewOperationReference[NoValue, int64]("op")
+	remaining, err := GetOperationResult(ctx, client, ref, "id", GetOperationResultOptions{Wait: time.Minute})
+	require.NoError(t, err)
+	require.Positive(t, remaining)
+
+	result, err := GetOperationResultWithDetails(ctx, client, ref, "id", GetOperationResultOptions{Wait: time.Minute})
+	require.NoError(t, err)
+	require.Positive(t, result.Result)
+	require.Equal(t, []Link{link}, result.Links)
+
+	_, err = GetOperationResult(ctx, client, ref, "", GetOperationResultOptions{})
+	require.ErrorIs(t, err, errEmptyOperationID)
+}
+
+func TestGetOperationResult_Unsuccessful(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{resultError: &UnsuccessfulOperationError{State: OperationStateFailed, Cause: errors.New("intentional")}})
+	defer teardown()
+
+	out, err := GetOperationResult(ctx, client, NewOperationReference[NoValue, string]("foo"), "a/sync", GetOperationResultOptions{})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, OperationStateFailed, unsuccessfulOperationError.State)
+	require.Zero(t, out)
+}
+
+type slowFirstResultHandler struct {
+	UnimplementedHandler
+	calls    atomic.Int32
+	canceled chan struct{}
+}
+
+func (h *slowFirstResultHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	if h.calls.Add(1) == 1 {
+		<-ctx.Done()
+		close(h.canceled)
+		return nil, ctx.Err()
+	}
+	return []byte("body"), nil
+}
+
+func TestPeekResult_Hedging(t *testing.T) {
+	handler := &slowFirstResultHandler{canceled: make(chan struct{})}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+	client.options.Hedging = HedgingOptions{Delay: 50 * time.Millisecond, MaxParallel: 2}
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	var body []byte
+	require.NoError(t, response.Consume(&body))
+	require.Equal(t, []byte("body"), body)
+	require.Equal(t, int32(2), handler.calls.Load())
+
+	select {
+	case <-handler.canceled:
+	case <-ctx.Done():
+		t.Fatal("hedged request was not canceled")
+	}
+}
+
+func TestPeekResult_HedgingAfterFailure(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+	defer teardown()
+	client.options.Hedging = HedgingOptions{Delay: time.Minute, MaxParallel: 2}
+	var calls atomic.Int32
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("connection reset")
+		}
+		return http.DefaultClient.Do(r)
+	}
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.NoError(t, response.Consume(new(any)))
+	require.Equal(t, int32(2), calls.Load())
+
+	// An error is returned once all attempts have failed.
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return nil, errors.New("connection reset")
+	}
+	calls.Store(0)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorContains(t, err, "connection reset")
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestWaitResult_MaxPollIterations(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+	defer teardown()
+
+	var calls int
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusRequestTimeout,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+		}, nil
+	}
+	client.options.MaxPollIterations = 3
+	client.options.LongPollBackoff = Backoff{InitialInterval: 50 * time.Millisecond, Coefficient: 1}
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	startTime := time.Now()
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute})
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.Equal(t, 3, calls)
+	require.GreaterOrEqual(t, time.Since(startTime), 2*client.options.LongPollBackoff.InitialInterval)
+}
+
+func TestWaitResult_LongPollBackoff(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+	defer teardown()
+
+	var callTimes []time.Time
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		callTimes = append(callTimes, time.Now())
+		return &http.Response{
+			StatusCode: http.StatusRequestTimeout,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+		}, nil
+	}
+	client.options.MaxPollIterations = 4
+	client.options.LongPollBackoff = Backoff{InitialInterval: 20 * time.Millisecond, MaxInterval: 50 * time.Millisecond}
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute})
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.Len(t, callTimes, 4)
+	for i, expected := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond} {
+		require.GreaterOrEqual(t, callTimes[i+1].Sub(callTimes[i]), expected)
+	}
+}
+
+func TestWaitResult_MergesLinksAcrossPolls(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+	defer teardown()
+
+	runningLink := Link{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/running"}, Type: "url"}
+	resultLink := Link{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/result"}, Type: "url"}
+	var calls int
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		calls++
+		header := http.Header{}
+		if calls == 1 {
+			require.NoError(t, addLinksToHTTPHeader([]Link{runningLink}, header))
+			return &http.Response{StatusCode: http.StatusRequestTimeout, Header: header, Body: http.NoBody}, nil
+		}
+		require.NoError(t, addLinksToHTTPHeader([]Link{runningLink, resultLink}, header))
+		header.Set("Content-Type", "application/json")
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(`"ok"`))}, nil
+	}
+	client.options.LongPollBackoff = Backoff{InitialInterval: -1}
+
+	ref := NewOperationReference[NoValue, string]("foo")
+	result, err := GetOperationResultWithDetails(ctx, client, ref, "a/sync", GetOperationResultOptions{Wait: time.Minute})
+	require.NoError(t, err)
+	require.Equal(t, "ok", result.Result)
+	require.Equal(t, []Link{runningLink, resultLink}, result.Links)
+	require.Equal(t, 2, calls)
+}
+
+func TestGetResult_InvalidLinksIgnored(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+	defer teardown()
+
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set(headerLink, "not a link")
+		header.Set("Content-Type", "application/json")
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(`"ok"`))}, nil
+	}
+
+	ref := NewOperationReference[NoValue, string]("foo")
+	result, err := GetOperationResultWithDetails(ctx, client, ref, "a/sync", GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "ok", result.Result)
+	require.Empty(t, result.Links)
+}
+
+func TestOutcomeMapper(t *testing.T) {
+	warningsAsSuccess := func(state OperationState, err error) (OperationState, error) {
+		if state == OperationStateFailed && strings.HasPrefix(err.Error(), "warning") {
+			return OperationStateSucceeded, nil
+		}
+		return state, err
+	}
+
+	t.Run("AsyncFailureToSuccess", func(t *testing.T) {
+		ctx, client, teardown := setup(t, &asyncWithResultHandler{resultError: &UnsuccessfulOperationError{State: OperationStateFailed, Cause: errors.New("warning: partial")}})
+		defer teardown()
+		client.options.OutcomeMapper = warningsAsSuccess
+
+		out, err := ExecuteOperation(ctx, client, NewOperationReference[NoValue, string]("foo"), nil, ExecuteOperationOptions{})
+		require.NoError(t, err)
+		require.Zero(t, out)
+	})
+
+	t.Run("SyncFailureToSuccess", func(t *testing.T) {
+		ctx, client, teardown := setup(t, &unsuccessfulHandler{})
+		defer teardown()
+		client.options.OutcomeMapper = func(state OperationState, err error) (OperationState, error) {
+			return OperationStateSucceeded, nil
+		}
+
+		_, err := ExecuteOperation(ctx, client, NewOperationReference[NoValue, []byte]("foo"), nil, ExecuteOperationOptions{RequestID: "failed"})
+		require.NoError(t, err)
+	})
+
+	t.Run("FailureUnchanged", func(t *testing.T) {
+		ctx, client, teardown := setup(t, &asyncWithResultHandler{resultError: &UnsuccessfulOperationError{State: OperationStateFailed, Cause: errors.New("fatal")}})
+		defer teardown()
+		client.options.OutcomeMapper = warningsAsSuccess
+
+		_, err := ExecuteOperation(ctx, client, NewOperationReference[NoValue, string]("foo"), nil, ExecuteOperationOptions{})
+		var unsuccessfulOperationError *UnsuccessfulOperationError
+		require.ErrorAs(t, err, &unsuccessfulOperationError)
+		require.Equal(t, OperationStateFailed, unsuccessfulOperationError.State)
+		require.ErrorContains(t, err, "fatal")
+	})
+
+	t.Run("SuccessToFailure", func(t *testing.T) {
+		ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+		defer teardown()
+		client.options.OutcomeMapper = func(state OperationState, err error) (OperationState, error) {
+			return OperationStateCanceled, errors.New("mapped")
+		}
+
+		handle, err := client.NewHandle("foo", "a/sync")
+		require.NoError(t, err)
+		_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+		var unsuccessfulOperationError *UnsuccessfulOperationError
+		require.ErrorAs(t, err, &unsuccessfulOperationError)
+		require.Equal(t, OperationStateCanceled, unsuccessfulOperationError.State)
+		require.EqualError(t, unsuccessfulOperationError.Cause, "mapped")
+	})
+}
+
+func TestWaitResult_OperationTimeoutIncludesWait(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{})
+	defer teardown()
+	client.options.OperationTimeouts = map[string]time.Duration{"foo": 100 * time.Millisecond}
+
+	var requestTimeout string
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		requestTimeout = r.Header.Get(HeaderRequestTimeout)
+		return http.DefaultClient.Do(r)
+	}
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	var body []byte
+	require.NoError(t, response.Consume(&body))
+	timeout, err := parseDuration(requestTimeout)
+	require.NoError(t, err)
+	require.Greater(t, timeout, time.Second)
+	require.LessOrEqual(t, timeout, 1100*time.Millisecond)
+}
+
+type progressReportingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *progressReportingHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultAsync{OperationID: "async"}, nil
+}
+
+func (h *progressReportingHandler) GetOperationInfo(ctx context.Context, service, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	return &OperationInfo{ID: operationID, State: OperationStateRunning}, nil
+}
+
+func (h *progressReportingHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	time.Sleep(300 * time.Millisecond)
+	return []byte("body"), nil
+}
+
+func TestExecuteOperation_OnProgress(t *testing.T) {
+	ctx, client, teardown := setup(t, &progressReportingHandler{})
+	defer teardown()
+
+	var infos []OperationInfo
+	response, err := client.ExecuteOperation(ctx, "foo", nil, ExecuteOperationOptions{
+		ProgressInterval: 50 * time.Millisecond,
+		OnProgress: func(info OperationInfo) {
+			infos = append(infos, info)
+		},
+	})
+	require.NoError(t, err)
+	var body []byte
+	require.NoError(t, response.Consume(&body))
+	require.Equal(t, []byte("body"), body)
+	require.NotEmpty(t, infos)
+	for _, info := range infos {
+		require.Equal(t, OperationInfo{ID: "async", State: OperationStateRunning}, info)
+	}
+}
+
+type resultMetadataHandler struct {
+	UnimplementedHandler
+}
+
+func (h *resultMetadataHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	return &Content{
+		Header:   Header{"type": "application/json"},
+		Data:     []byte(`"ok"`),
+		Metadata: Header{"audit-id": "123", "region": "us"},
+	}, nil
+}
+
+func TestGetResult_Metadata(t *testing.T) {
+	ctx, client, teardown := setup(t, &resultMetadataHandler{})
+	defer teardown()
+
+	ref := NewOperationReference[NoValue, string]("foo")
+	result, err := GetOperationResultWithDetails(ctx, client, ref, "a/sync", GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "ok", result.Result)
+	require.Equal(t, Header{"audit-id": "123", "region": "us"}, result.Metadata)
+}
+
+var executeDetailsLink = Link{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/start"}, Type: "url"}
+
+type executeDetailsHandler struct {
+	UnimplementedHandler
+}
+
+func (h *executeDetailsHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	switch operation {
+	case "sync":
+		return &HandlerStartOperationResultSync[any]{Value: "sync", Links: []Link{executeDetailsLink}}, nil
+	case "failed":
+		return nil, NewFailedOperationError(errors.New("failed"))
+	default:
+		return &HandlerStartOperationResultAsync{OperationID: operation, Links: []Link{executeDetailsLink}}, nil
+	}
+}
+
+func (h *executeDetailsHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	if operationID == "canceled" {
+		return nil, NewCanceledOperationError(errors.New("canceled"))
+	}
+	return "async", nil
+}
+
+func TestExecuteOperationWithDetails(t *testing.T) {
+	ctx, client, teardown := setup(t, &executeDetailsHandler{})
+	defer teardown()
+
+	result, err := client.ExecuteOperationWithDetails(ctx, "sync", nil, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	var value string
+	require.NoError(t, result.Value.Consume(&value))
+	require.Equal(t, "sync", value)
+	require.Equal(t, []Link{executeDetailsLink}, result.Links)
+	require.Equal(t, OperationStateSucceeded, result.State)
+	require.True(t, result.Synchronous)
+
+	result, err = client.ExecuteOperationWithDetails(ctx, "async", nil, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.NoError(t, result.Value.Consume(&value))
+	require.Equal(t, "async", value)
+	require.Equal(t, []Link{executeDetailsLink}, result.Links)
+	require.Equal(t, OperationStateSucceeded, result.State)
+	require.False(t, result.Synchronous)
+
+	result, err = client.ExecuteOperationWithDetails(ctx, "failed", nil, ExecuteOperationOptions{})
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, OperationStateFailed, result.State)
+	require.True(t, result.Synchronous)
+
+	result, err = client.ExecuteOperationWithDetails(ctx, "canceled", nil, ExecuteOperationOptions{})
+	require.ErrorAs(t, err, &unsuccessfulOperationError)
+	require.Equal(t, OperationStateCanceled, result.State)
+	require.Equal(t, []Link{executeDetailsLink}, result.Links)
+	require.False(t, result.Synchronous)
+}
+
+type eventuallyCompletingHandler struct {
+	UnimplementedHandler
+	timesStillRunning int32
+	calls             atomic.Int32
+}
+
+func (h *eventuallyCompletingHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	if h.calls.Add(1) <= h.timesStillRunning {
+		return nil, ErrOperationStillRunning
+	}
+	return "done", nil
+}
+
+func TestWait(t *testing.T) {
+	handler := &eventuallyCompletingHandler{timesStillRunning: 3}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+	observer := &recordingObserver{}
+	client.options.Observer = observer
+	client.options.MaxPollIterations = 1
+	client.options.LongPollBackoff = Backoff{InitialInterval: time.Millisecond, Coefficient: 1}
+
+	handle, err := NewHandle(client, NewOperationReference[NoValue, string]("foo"), "id")
+	require.NoError(t, err)
+	result, err := handle.Wait(ctx, WaitOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "done", result)
+	require.Equal(t, int32(4), handler.calls.Load())
+	// A Wait call is reported once, like a long polling GetResult call.
+	require.Equal(t, []observedCall{{"result", testService, "foo", Outcome{Kind: OutcomeSucceeded}}}, observer.calls)
+}
+
+func TestWait_DeadlineExceeded(t *testing.T) {
+	_, client, teardown := setup(t, &eventuallyCompletingHandler{timesStillRunning: math.MaxInt32})
+	defer teardown()
+	client.options.MaxPollIterations = 1
+	client.options.LongPollBackoff = Backoff{InitialInterval: 10 * time.Millisecond, Coefficient: 1}
+
+	handle, err := NewHandle(client, NewOperationReference[NoValue, string]("foo"), "id")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = handle.Wait(ctx, WaitOptions{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -3,8 +3,12 @@ package nexus
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,35 +21,90 @@ type OperationHandle[T any] struct {
 	// Handler generated ID for this handle's operation.
 	ID     string
 	client *HTTPClient
+	// Set for handles of operations that completed synchronously, see [HTTPClient.StartOperationHandle].
+	resolved bool
+	// The inline result of a synchronously completed operation, unset once returned from GetResult.
+	result atomic.Pointer[LazyValue]
+	// The information last returned by GetInfo if it has an ETag, used to make conditional get info requests.
+	cachedInfo atomic.Pointer[OperationInfo]
+}
+
+var errResultAlreadyConsumed = errors.New("operation result already consumed")
+
+var errOperationAlreadyCompleted = errors.New("operation already completed")
+
+// lazyValueToResult returns v as T if T is a [LazyValue], otherwise it consumes v into a T.
+func lazyValueToResult[T any](v *LazyValue) (T, error) {
+	var result T
+	if _, ok := any(result).(*LazyValue); ok {
+		return any(v).(T), nil
+	}
+	return result, v.Consume(&result)
 }
 
 // GetInfo gets operation information, issuing a network request to the service handler.
+//
+// For handles of synchronously completed operations, returns the succeeded state without issuing a network request.
+//
+// If the handler attached an ETag to the information last returned by the handle, the request is conditional and the
+// cached information is returned if the handler reports it unchanged, see OperationInfo.ETag.
 func (h *OperationHandle[T]) GetInfo(ctx context.Context, options GetOperationInfoOptions) (*OperationInfo, error) {
+	start := time.Now()
+	ctx, cancel := h.client.withOperationTimeout(ctx, h.Operation, 0)
+	defer cancel()
+	info, err := h.getInfo(ctx, options)
+	h.client.observe(ClientObserver.GetOperationInfoDone, h.Operation, start, outcomeFromError(err))
+	return info, h.client.annotateError(h.Operation, err)
+}
+
+func (h *OperationHandle[T]) getInfo(ctx context.Context, options GetOperationInfoOptions) (*OperationInfo, error) {
+	if h.resolved {
+		return &OperationInfo{ID: h.ID, State: OperationStateSucceeded}, nil
+	}
 	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.client.options.Service), url.PathEscape(h.Operation), url.PathEscape(h.ID))
-	request, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+	request, err := h.client.newRequest(ctx, "GET", url.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	h.client.addDeadlineToHTTPHeader(ctx, request.Header)
+	cached := h.cachedInfo.Load()
+	if cached != nil {
+		request.Header.Set("If-None-Match", cached.ETag)
+	}
 	addNexusHeaderToHTTPHeader(options.Header, request.Header)
 
 	request.Header.Set(headerUserAgent, userAgent)
-	response, err := h.client.options.HTTPCaller(request)
+	response, err := h.client.sendRequest(request)
 	if err != nil {
 		return nil, err
 	}
 
 	// Do this once here and make sure it doesn't leak.
-	body, err := readAndReplaceBody(response)
+	body, err := h.client.readAndReplaceBody(response)
 	if err != nil {
 		return nil, err
 	}
 
+	if response.StatusCode == http.StatusNotModified && cached != nil {
+		info := *cached
+		return &info, nil
+	}
 	if response.StatusCode != http.StatusOK {
 		return nil, h.client.bestEffortHandlerErrorFromResponse(response, body)
 	}
 
-	return operationInfoFromResponse(response, body)
+	info, err := operationInfoFromResponse(response, body)
+	if err != nil {
+		return nil, err
+	}
+	info.ETag = response.Header.Get("ETag")
+	if info.ETag != "" {
+		cachedInfo := *info
+		h.cachedInfo.Store(&cachedInfo)
+	} else {
+		h.cachedInfo.Store(nil)
+	}
+	return info, nil
 }
 
 // GetResult gets the result of an operation, issuing a network request to the service handler.
@@ -63,21 +122,75 @@ func (h *OperationHandle[T]) GetInfo(ctx context.Context, options GetOperationIn
 // Note that the wait period is enforced by the server and may not be respected if the server is misbehaving. Set the
 // context deadline to the max allowed wait period to ensure this call returns in a timely fashion.
 //
+// For handles of synchronously completed operations, returns the inline result without issuing a network request. The
+// result can only be returned once.
+//
 // ⚠️ If a [LazyValue] is returned (as indicated by T), it must be consumed to free up the underlying connection.
 func (h *OperationHandle[T]) GetResult(ctx context.Context, options GetOperationResultOptions) (T, error) {
+	result, _, _, err := h.getResultWithOperationTimeout(ctx, options, false)
+	return result, h.client.annotateError(h.Operation, err)
+}
+
+// Wait blocks until the operation reaches a terminal state and returns its result. Wait is a long polling GetResult
+// call without a wait limit: requests the server times out, or responds to with the operation still running, are
+// reissued after the client's LongPollBackoff, and HTTPClientOptions.MaxPollIterations does not apply. Unlike
+// GetResult, Wait never returns ErrOperationStillRunning. The call is reported once to the client's observer.
+//
+// Returns an [UnsuccessfulOperationError] if the operation failed or was canceled. Returns an error wrapping the
+// context's error, e.g. [context.DeadlineExceeded], if the context is done before the operation completes. Set a
+// context deadline to bound the wait.
+//
+// ⚠️ If a [LazyValue] is returned (as indicated by T), it must be consumed to free up the underlying connection.
+func (h *OperationHandle[T]) Wait(ctx context.Context, options WaitOptions) (T, error) {
+	gro := GetOperationResultOptions{Header: options.Header, Wait: time.Duration(math.MaxInt64)}
+	result, _, _, err := h.getResultWithOperationTimeout(ctx, gro, true)
+	return result, h.client.annotateError(h.Operation, err)
+}
+
+// getResultWithOperationTimeout calls getResult with the client's operation timeout applied. The timeout is canceled
+// once a returned [LazyValue] is closed.
+func (h *OperationHandle[T]) getResultWithOperationTimeout(ctx context.Context, options GetOperationResultOptions, untilTerminal bool) (T, []Link, Header, error) {
+	start := time.Now()
+	ctx, cancel := h.client.withOperationTimeout(ctx, h.Operation, options.Wait)
+	result, links, metadata, err := h.getResult(ctx, options, untilTerminal)
+	h.client.observe(ClientObserver.GetOperationResultDone, h.Operation, start, outcomeFromError(err))
+	if value, ok := any(result).(*LazyValue); ok && value != nil && err == nil {
+		value.Reader.ReadCloser = &cancelOnCloseBody{value.Reader.ReadCloser, cancel}
+	} else {
+		cancel()
+	}
+	return result, links, metadata, err
+}
+
+// getResult implements GetResult, also returning the links and metadata attached to the result response. If
+// untilTerminal is set, long polls continue past MaxPollIterations and still running responses until the operation
+// completes or the wait period exceeds.
+func (h *OperationHandle[T]) getResult(ctx context.Context, options GetOperationResultOptions, untilTerminal bool) (T, []Link, Header, error) {
 	var result T
+	if h.resolved {
+		v := h.result.Swap(nil)
+		if v == nil {
+			return result, nil, nil, errResultAlreadyConsumed
+		}
+		result, err := lazyValueToResult[T](v)
+		return result, nil, nil, err
+	}
 	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.client.options.Service), url.PathEscape(h.Operation), url.PathEscape(h.ID), "result")
-	request, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+	request, err := h.client.newRequest(ctx, "GET", url.String(), nil)
 	if err != nil {
-		return result, err
+		return result, nil, nil, err
 	}
-	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	h.client.addDeadlineToHTTPHeader(ctx, request.Header)
 	request.Header.Set(headerUserAgent, userAgent)
+	addAcceptToHTTPHeader(h.client.options.Serializer, request.Header)
 	addNexusHeaderToHTTPHeader(options.Header, request.Header)
 
 	startTime := time.Now()
 	wait := options.Wait
-	for {
+	// Links seen across all poll iterations, in the order they were first seen.
+	var links []Link
+	for iteration := 1; ; iteration++ {
+		requestStartTime := time.Now()
 		if wait > 0 {
 			if deadline, set := ctx.Deadline(); set {
 				// Ensure we don't wait longer than the deadline but give some buffer prevent racing between wait and
@@ -94,33 +207,171 @@ func (h *OperationHandle[T]) GetResult(ctx context.Context, options GetOperation
 			request.URL.RawQuery = ""
 		}
 
-		response, err := h.sendGetOperationResultRequest(request)
+		var response *http.Response
+		if wait > 0 {
+			response, err = h.sendGetOperationResultRequest(request)
+		} else {
+			response, err = h.sendHedgedGetOperationResultRequest(request)
+		}
 		if err != nil {
-			if wait > 0 && errors.Is(err, errOperationWaitTimeout) {
-				// TODO: Backoff a bit in case the server is continually returning timeouts due to some LB configuration
+			if wait > 0 && (errors.Is(err, errOperationWaitTimeout) || untilTerminal && errors.Is(err, ErrOperationStillRunning)) {
+				// Links on intermediate responses are best effort, ignore invalid headers.
+				if response != nil {
+					if intermediateLinks, err := getLinksFromHeader(response.Header); err == nil {
+						links = mergeLinks(links, intermediateLinks)
+					}
+				}
+				maxIterations := h.client.options.MaxPollIterations
+				if !untilTerminal && maxIterations > 0 && iteration >= maxIterations {
+					return result, nil, nil, ErrOperationStillRunning
+				}
+				// Backoff a bit in case the server is continually returning timeouts due to some LB configuration
 				// issue to avoid blowing it up with repeated calls.
+				if backoff := h.client.options.LongPollBackoff.Delay(iteration) - time.Since(requestStartTime); backoff > 0 {
+					timer := time.NewTimer(backoff)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return result, nil, nil, requestCanceledError(ctx, ctx.Err())
+					case <-timer.C:
+					}
+				}
 				wait = options.Wait - time.Since(startTime)
 				continue
 			}
-			return result, err
+			value, err := h.client.mapOutcome(nil, err)
+			if err != nil {
+				return result, nil, nil, err
+			}
+			result, err = lazyValueToResult[T](value)
+			return result, links, nil, err
 		}
+		// Links on result responses are best effort as well, ignore invalid headers rather than failing to return the
+		// result.
+		resultLinks, _ := getLinksFromHeader(response.Header)
 		s := &LazyValue{
 			serializer: h.client.options.Serializer,
 			Reader: &Reader{
-				response.Body,
+				h.client.limitResponseBody(response.Body),
 				prefixStrippedHTTPHeaderToNexusHeader(response.Header, "content-"),
 			},
 		}
-		if _, ok := any(result).(*LazyValue); ok {
-			return any(s).(T), nil
-		} else {
-			return result, s.Consume(&result)
+		value, err := h.client.mapOutcome(s, nil)
+		if err != nil {
+			return result, nil, nil, err
 		}
+		result, err = lazyValueToResult[T](value)
+		metadata := prefixStrippedHTTPHeaderToNexusHeader(response.Header, headerResultMetadataPrefix)
+		return result, mergeLinks(links, resultLinks), metadata, err
 	}
 }
 
+type hedgedResponse struct {
+	attempt  int
+	response *http.Response
+	err      error
+}
+
+// cancelOnCloseBody cancels the context of a request once the body of its response is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// sendHedgedGetOperationResultRequest sends the given request, issuing additional requests according to the client's
+// HedgingOptions and returning the first successful or terminal outcome. Failed requests are hedged immediately, the
+// last error is returned once all requests have failed.
+func (h *OperationHandle[T]) sendHedgedGetOperationResultRequest(request *http.Request) (*http.Response, error) {
+	hedging := h.client.options.Hedging
+	if hedging.Delay <= 0 || hedging.MaxParallel < 2 {
+		return h.sendGetOperationResultRequest(request)
+	}
+
+	responses := make(chan hedgedResponse, hedging.MaxParallel)
+	cancels := make([]context.CancelFunc, 0, hedging.MaxParallel)
+	send := func() {
+		ctx, cancel := context.WithCancel(request.Context())
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			response, err := h.sendGetOperationResultRequest(request.Clone(ctx))
+			responses <- hedgedResponse{attempt, response, err}
+		}()
+	}
+
+	send()
+	timer := time.NewTimer(hedging.Delay)
+	defer timer.Stop()
+	failed := 0
+	for {
+		select {
+		case <-timer.C:
+			send()
+			if len(cancels) < hedging.MaxParallel {
+				timer.Reset(hedging.Delay)
+			}
+		case winner := <-responses:
+			if !isTerminalGetOperationResultOutcome(winner.err) {
+				cancels[winner.attempt]()
+				failed++
+				if failed < len(cancels) {
+					// Other requests are still in flight.
+					continue
+				}
+				if len(cancels) == hedging.MaxParallel {
+					return nil, winner.err
+				}
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				send()
+				if len(cancels) < hedging.MaxParallel {
+					timer.Reset(hedging.Delay)
+				}
+				continue
+			}
+			for attempt, cancel := range cancels {
+				if attempt != winner.attempt {
+					cancel()
+				}
+			}
+			// Drain and close responses of the canceled requests to free up their connections.
+			go func(pending int) {
+				for ; pending > 0; pending-- {
+					if loser := <-responses; loser.response != nil {
+						_, _ = io.Copy(io.Discard, loser.response.Body)
+						_ = loser.response.Body.Close()
+					}
+				}
+			}(len(cancels) - failed - 1)
+			if winner.response == nil {
+				cancels[winner.attempt]()
+				return nil, winner.err
+			}
+			// The winner's context must remain valid until the body is read.
+			winner.response.Body = &cancelOnCloseBody{winner.response.Body, cancels[winner.attempt]}
+			return winner.response, winner.err
+		}
+	}
+}
+
+// isTerminalGetOperationResultOutcome reports whether err, returned from sendGetOperationResultRequest, is a definitive
+// answer from the server: a result, an unsuccessful operation, or an operation that is still running.
+func isTerminalGetOperationResultOutcome(err error) bool {
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	return err == nil || errors.Is(err, ErrOperationStillRunning) || errors.As(err, &unsuccessfulOperationError)
+}
+
 func (h *OperationHandle[T]) sendGetOperationResultRequest(request *http.Request) (*http.Response, error) {
-	response, err := h.client.options.HTTPCaller(request)
+	response, err := h.client.sendRequest(request)
 	if err != nil {
 		return nil, err
 	}
@@ -130,14 +381,15 @@ func (h *OperationHandle[T]) sendGetOperationResultRequest(request *http.Request
 	}
 
 	// Do this once here and make sure it doesn't leak.
-	body, err := readAndReplaceBody(response)
+	body, err := h.client.readAndReplaceBody(response)
 	if err != nil {
 		return nil, err
 	}
 
 	switch response.StatusCode {
 	case http.StatusRequestTimeout:
-		return nil, errOperationWaitTimeout
+		// Return the response so the caller can collect any links attached to it. The body has already been read.
+		return response, errOperationWaitTimeout
 	case statusOperationRunning:
 		return nil, ErrOperationStillRunning
 	case statusOperationFailed:
@@ -151,8 +403,9 @@ func (h *OperationHandle[T]) sendGetOperationResultRequest(request *http.Request
 		}
 		failureErr := h.client.options.FailureConverter.FailureToError(failure)
 		return nil, &UnsuccessfulOperationError{
-			State: state,
-			Cause: failureErr,
+			State:     state,
+			Cause:     failureErr,
+			Retryable: getRetryableFromHeader(response.Header),
 		}
 	default:
 		return nil, h.client.bestEffortHandlerErrorFromResponse(response, body)
@@ -162,27 +415,114 @@ func (h *OperationHandle[T]) sendGetOperationResultRequest(request *http.Request
 // Cancel requests to cancel an asynchronous operation.
 //
 // Cancelation is asynchronous and may be not be respected by the operation's implementation.
-func (h *OperationHandle[T]) Cancel(ctx context.Context, options CancelOperationOptions) error {
-	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.client.options.Service), url.PathEscape(h.Operation), url.PathEscape(h.ID), "cancel")
-	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), nil)
+//
+// For handles of synchronously completed operations, this is a no-op since the operation has already completed.
+//
+// On success, the returned result is never nil and contains any links and metadata attached by the handler.
+func (h *OperationHandle[T]) Cancel(ctx context.Context, options CancelOperationOptions) (*CancelOperationResult, error) {
+	start := time.Now()
+	ctx, cancel := h.client.withOperationTimeout(ctx, h.Operation, 0)
+	defer cancel()
+	result, err := h.cancel(ctx, options)
+	h.client.observe(ClientObserver.CancelOperationDone, h.Operation, start, outcomeFromError(err))
+	return result, h.client.annotateError(h.Operation, err)
+}
+
+func (h *OperationHandle[T]) cancel(ctx context.Context, options CancelOperationOptions) (*CancelOperationResult, error) {
+	if h.resolved {
+		return &CancelOperationResult{Metadata: Header{}}, nil
+	}
+	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.client.options.Service), url.PathEscape(h.Operation), url.PathEscape(h.ID))
+	method := "DELETE"
+	if !h.client.options.CancelWithDelete {
+		url = url.JoinPath("cancel")
+		method = "POST"
+	}
+	request, err := h.client.newRequest(ctx, method, url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	h.client.addDeadlineToHTTPHeader(ctx, request.Header)
+	request.Header.Set(headerUserAgent, userAgent)
+	addNexusHeaderToHTTPHeader(options.Header, request.Header)
+	response, err := h.client.sendRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := h.client.readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusAccepted {
+		return nil, h.client.bestEffortHandlerErrorFromResponse(response, body)
+	}
+	links, err := getLinksFromHeader(response.Header)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: %w",
+			newUnexpectedResponseError(
+				fmt.Sprintf("invalid links header: %q", response.Header.Values(headerLink)),
+				response,
+				body,
+			),
+			err,
+		)
+	}
+	return &CancelOperationResult{
+		Links:    links,
+		Metadata: prefixStrippedHTTPHeaderToNexusHeader(response.Header, headerCancelMetadataPrefix),
+	}, nil
+}
+
+// RegisterCallback registers an additional callback URL to deliver the completion of an asynchronous operation to.
+//
+// This can be used by callers that could not provide a callback URL when starting the operation. Handlers may limit
+// the number of callbacks registered per operation.
+//
+// Fails for handles of synchronously completed operations.
+func (h *OperationHandle[T]) RegisterCallback(ctx context.Context, options RegisterOperationCallbackOptions) error {
+	start := time.Now()
+	ctx, cancel := h.client.withOperationTimeout(ctx, h.Operation, 0)
+	defer cancel()
+	err := h.registerCallback(ctx, options)
+	h.client.observe(ClientObserver.RegisterOperationCallbackDone, h.Operation, start, outcomeFromError(err))
+	return h.client.annotateError(h.Operation, err)
+}
+
+func (h *OperationHandle[T]) registerCallback(ctx context.Context, options RegisterOperationCallbackOptions) error {
+	if h.resolved {
+		return errOperationAlreadyCompleted
+	}
+	if options.CallbackURL == "" {
+		return errors.New("empty CallbackURL")
+	}
+	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.client.options.Service), url.PathEscape(h.Operation), url.PathEscape(h.ID), "callbacks")
+	q := url.Query()
+	q.Set(queryCallbackURL, options.CallbackURL)
+	url.RawQuery = q.Encode()
+	request, err := h.client.newRequest(ctx, "POST", url.String(), nil)
 	if err != nil {
 		return err
 	}
-	addContextTimeoutToHTTPHeader(ctx, request.Header)
+	h.client.addDeadlineToHTTPHeader(ctx, request.Header)
 	request.Header.Set(headerUserAgent, userAgent)
+	addCallbackHeaderToHTTPHeader(options.CallbackHeader, request.Header)
 	addNexusHeaderToHTTPHeader(options.Header, request.Header)
-	response, err := h.client.options.HTTPCaller(request)
+	response, err := h.client.sendRequest(request)
 	if err != nil {
 		return err
 	}
 
 	// Do this once here and make sure it doesn't leak.
-	body, err := readAndReplaceBody(response)
+	body, err := h.client.readAndReplaceBody(response)
 	if err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusAccepted {
+	if response.StatusCode != http.StatusOK {
 		return h.client.bestEffortHandlerErrorFromResponse(response, body)
 	}
 	return nil
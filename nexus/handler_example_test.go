@@ -59,7 +59,7 @@ func (h *myHandler) GetOperationResult(ctx context.Context, service, operation,
 	}
 }
 
-func (h *myHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options nexus.CancelOperationOptions) error {
+func (h *myHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options nexus.CancelOperationOptions) (*nexus.CancelOperationResult, error) {
 	// Handlers must implement this.
 	panic("unimplemented")
 }
@@ -0,0 +1,271 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// BodyLoggingMiddlewareOptions are options for [NewBodyLoggingMiddleware].
+type BodyLoggingMiddlewareOptions struct {
+	// A stuctured logger. Bodies are logged at debug level.
+	// Defaults to slog.Default().
+	Logger *slog.Logger
+	// Maximum number of input bytes to capture for logging. The input is streamed to the operation and only up to
+	// this many bytes are retained by the middleware. Inputs exceeding this size are logged as truncated and are not
+	// decoded. Inputs the operation did not read to the end are logged as incomplete and are not decoded either.
+	//
+	// Defaults to 4 KiB.
+	MaxBodySize int
+	// An optional function applied to decoded inputs and outputs before they are logged, e.g. to mask sensitive
+	// fields. The returned value is logged instead of the given value.
+	Redact func(info HandlerInfo, value any) any
+	// An optional predicate to select which operations should be logged. Use this to avoid logging sensitive
+	// operations.
+	//
+	// Defaults to logging all operations.
+	ShouldLog func(info HandlerInfo) bool
+}
+
+const defaultBodyLoggingMaxBodySize = 4 * 1024
+
+// NewBodyLoggingMiddleware creates a [MiddlewareFunc] that logs operation inputs and outputs at debug level.
+//
+// Inputs are captured while the operation reads them, preserving the single-read contract of [LazyValue], and are
+// decoded with the handler's [Serializer] for logging after the operation's Start method returns. Outputs of
+// StartOperation and GetOperationResult are logged as returned by the operation; [*Reader] and [*Content] outputs are
+// not read by the middleware.
+//
+// The middleware is a no-op when the logger is not enabled for debug level.
+func NewBodyLoggingMiddleware(options BodyLoggingMiddlewareOptions) MiddlewareFunc {
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = defaultBodyLoggingMaxBodySize
+	}
+	return func(ctx context.Context, next Handler) (Handler, error) {
+		if !options.Logger.Enabled(ctx, slog.LevelDebug) {
+			return next, nil
+		}
+		info, _ := ExtractHandlerInfo(ctx)
+		if options.ShouldLog != nil && !options.ShouldLog(info) {
+			return next, nil
+		}
+		return &bodyLoggingHandler{Handler: next, options: options, info: info}, nil
+	}
+}
+
+type bodyLoggingHandler struct {
+	Handler
+	options BodyLoggingMiddlewareOptions
+	info    HandlerInfo
+}
+
+// StartOperation implements Handler.
+func (h *bodyLoggingHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	capture := &cappedBuffer{limit: h.options.MaxBodySize}
+	// Inputs that are not read are logged as empty.
+	tee := &eofReader{eof: true}
+	if input.Reader.ReadCloser != nil {
+		tee = &eofReader{Reader: io.TeeReader(input.Reader.ReadCloser, capture)}
+		input = &LazyValue{
+			serializer: input.serializer,
+			Reader: &Reader{
+				ReadCloser: struct {
+					io.Reader
+					io.Closer
+				}{tee, input.Reader.ReadCloser},
+				Header: input.Reader.Header,
+			},
+		}
+	}
+
+	result, err := h.Handler.StartOperation(ctx, service, operation, input, options)
+
+	attrs := []any{"service", service, "operation", operation}
+	if options.DryRun {
+		attrs = append(attrs, "dry_run", true)
+	}
+	if capture.truncated {
+		attrs = append(attrs, "input_truncated", true)
+	} else if !tee.eof {
+		// The operation read only part of its input, don't decode a prefix of it.
+		attrs = append(attrs, "input_incomplete", true)
+	} else {
+		var v any
+		if err := input.serializer.Deserialize(&Content{Header: input.Reader.Header, Data: capture.Bytes()}, &v); err != nil {
+			attrs = append(attrs, "input_error", err)
+		} else {
+			attrs = append(attrs, "input", h.redact(v))
+		}
+	}
+	switch r := result.(type) {
+	case SyncStartOperationResult:
+		attrs = append(attrs, "output", h.loggableOutput(r.ResultValue()))
+	case *HandlerStartOperationResultAsync:
+		attrs = append(attrs, "operation_id", r.OperationID)
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	h.options.Logger.DebugContext(ctx, "start operation", attrs...)
+	return result, err
+}
+
+// GetOperationResult implements Handler.
+func (h *bodyLoggingHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	result, err := h.Handler.GetOperationResult(ctx, service, operation, operationID, options)
+	attrs := []any{"service", service, "operation", operation, "operation_id", operationID}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	} else {
+		attrs = append(attrs, "output", h.loggableOutput(result))
+	}
+	h.options.Logger.DebugContext(ctx, "get operation result", attrs...)
+	return result, err
+}
+
+func (h *bodyLoggingHandler) loggableOutput(v any) any {
+	switch v.(type) {
+	case *Reader, *Content:
+		return "<unread>"
+	}
+	return h.redact(v)
+}
+
+func (h *bodyLoggingHandler) redact(v any) any {
+	if h.options.Redact == nil {
+		return v
+	}
+	return h.options.Redact(h.info, v)
+}
+
+// eofReader is an [io.Reader] that records whether it was read to the end.
+type eofReader struct {
+	io.Reader
+	eof bool
+}
+
+func (r *eofReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.eof = true
+	}
+	return n, err
+}
+
+// cappedBuffer is an [io.Writer] that retains up to max bytes and discards the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); len(p) > remaining {
+		b.truncated = true
+		b.Buffer.Write(p[:remaining])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// NewRecoverMiddleware creates a [MiddlewareFunc] that recovers panics in handler methods, logging the panic value and
+// stack trace with the given logger, or slog.Default() if nil. Recovered panics are returned to the caller as a
+// [HandlerErrorTypeInternal] error with a generic message, the same way arbitrary errors are.
+//
+// Register it first with [ServiceRegistry.Use] to also recover panics in middleware registered after it.
+func NewRecoverMiddleware(logger *slog.Logger) MiddlewareFunc {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(ctx context.Context, next Handler) (Handler, error) {
+		return &recoveringHandler{Handler: next, logger: logger}, nil
+	}
+}
+
+type recoveringHandler struct {
+	Handler
+	logger *slog.Logger
+}
+
+// recover converts a panic into an internal handler error assigned to err. Must be called directly by a deferred
+// function.
+func (h *recoveringHandler) recover(ctx context.Context, method, service, operation string, err *error) {
+	if r := recover(); r != nil {
+		h.logger.ErrorContext(ctx, "handler panicked", "method", method, "service", service, "operation", operation, "panic", r, "stack", string(debug.Stack()))
+		*err = HandlerErrorf(HandlerErrorTypeInternal, "internal server error")
+	}
+}
+
+// StartOperation implements Handler.
+func (h *recoveringHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (result HandlerStartOperationResult[any], err error) {
+	defer h.recover(ctx, "StartOperation", service, operation, &err)
+	return h.Handler.StartOperation(ctx, service, operation, input, options)
+}
+
+// GetOperationResult implements Handler.
+func (h *recoveringHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (result any, err error) {
+	defer h.recover(ctx, "GetOperationResult", service, operation, &err)
+	return h.Handler.GetOperationResult(ctx, service, operation, operationID, options)
+}
+
+// GetOperationInfo implements Handler.
+func (h *recoveringHandler) GetOperationInfo(ctx context.Context, service, operation, operationID string, options GetOperationInfoOptions) (info *OperationInfo, err error) {
+	defer h.recover(ctx, "GetOperationInfo", service, operation, &err)
+	return h.Handler.GetOperationInfo(ctx, service, operation, operationID, options)
+}
+
+// CancelOperation implements Handler.
+func (h *recoveringHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) (result *CancelOperationResult, err error) {
+	defer h.recover(ctx, "CancelOperation", service, operation, &err)
+	return h.Handler.CancelOperation(ctx, service, operation, operationID, options)
+}
+
+// RegisterOperationCallback implements Handler.
+func (h *recoveringHandler) RegisterOperationCallback(ctx context.Context, service, operation, operationID string, options RegisterOperationCallbackOptions) (err error) {
+	defer h.recover(ctx, "RegisterOperationCallback", service, operation, &err)
+	return h.Handler.RegisterOperationCallback(ctx, service, operation, operationID, options)
+}
+
+// NewTimeoutMiddleware creates a [MiddlewareFunc] that limits the duration of operation Start methods to max, to
+// protect a service from runaway synchronous operations.
+//
+// The operation is started with a context that expires after max, or earlier if the request's context has an earlier
+// deadline, e.g. one derived from the Request-Timeout header. If the operation's Start method fails after the limit
+// expired, its error is replaced with a [HandlerErrorTypeUpstreamTimeout] error. Successful results are returned as
+// is, even if the limit expired. Requests whose own deadline expired first are not affected.
+//
+// Operations must respect the context's deadline to be aborted in a timely fashion; the middleware does not interrupt
+// a Start method that ignores it.
+func NewTimeoutMiddleware(max time.Duration) MiddlewareFunc {
+	return func(ctx context.Context, next Handler) (Handler, error) {
+		if max <= 0 {
+			return next, nil
+		}
+		return &timeoutHandler{Handler: next, max: max}, nil
+	}
+}
+
+type timeoutHandler struct {
+	Handler
+	max time.Duration
+}
+
+// StartOperation implements Handler.
+func (h *timeoutHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, h.max)
+	defer cancel()
+	result, err := h.Handler.StartOperation(timeoutCtx, service, operation, input, options)
+	// Successful results are returned as is, an operation that was started asynchronously must be reported to the
+	// caller even if starting it took too long.
+	if err != nil && ctx.Err() == nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return nil, HandlerErrorf(HandlerErrorTypeUpstreamTimeout, "operation timed out")
+	}
+	return result, err
+}
@@ -0,0 +1,383 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMiddlewareHandler struct {
+	Handler
+	name   string
+	record *[]string
+}
+
+func (h *recordingMiddlewareHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	*h.record = append(*h.record, h.name)
+	return h.Handler.StartOperation(ctx, service, operation, input, options)
+}
+
+func recordingMiddleware(name string, record *[]string) MiddlewareFunc {
+	return func(ctx context.Context, next Handler) (Handler, error) {
+		info, ok := ExtractHandlerInfo(ctx)
+		if !ok {
+			return nil, HandlerErrorf(HandlerErrorTypeInternal, "missing handler info")
+		}
+		if info.Service != testService || info.Operation != numberValidatorOperation.Name() {
+			return nil, HandlerErrorf(HandlerErrorTypeInternal, "unexpected handler info: %v", info)
+		}
+		return &recordingMiddlewareHandler{Handler: next, name: name, record: record}, nil
+	}
+}
+
+func TestMiddleware_Order(t *testing.T) {
+	var record []string
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(numberValidatorOperation))
+	require.NoError(t, registry.Register(svc))
+	registry.Use(recordingMiddleware("first", &record), recordingMiddleware("second", &record))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+	require.Equal(t, []string{"first", "second"}, record)
+}
+
+func TestMiddleware_UseFor(t *testing.T) {
+	var record []string
+	recorder := func(name string) MiddlewareFunc {
+		return func(ctx context.Context, next Handler) (Handler, error) {
+			return &recordingMiddlewareHandler{Handler: next, name: name, record: &record}, nil
+		}
+	}
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(numberValidatorOperation, bytesIOOperation))
+	require.NoError(t, registry.Register(svc))
+	registry.Use(recorder("global-first"))
+	registry.UseFor([]string{numberValidatorOperation.Name()}, recorder("scoped"))
+	registry.Use(recorder("global-last"))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err = ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"global-first", "scoped", "global-last"}, record)
+
+	record = nil
+	_, err = ExecuteOperation(ctx, client, bytesIOOperation, []byte("hello"), ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"global-first", "global-last"}, record)
+}
+
+func TestMiddleware_Error(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(numberValidatorOperation))
+	require.NoError(t, registry.Register(svc))
+	registry.Use(func(ctx context.Context, next Handler) (Handler, error) {
+		return nil, HandlerErrorf(HandlerErrorTypeUnauthorized, "go away")
+	})
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err = ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeUnauthorized, handlerError.Type)
+	require.Equal(t, "go away", handlerError.Cause.Error())
+}
+
+type secretInput struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+var echoSecretOperation = NewSyncOperation("echo-secret", func(ctx context.Context, input secretInput, options StartOperationOptions) (secretInput, error) {
+	return input, nil
+})
+
+func TestBodyLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(echoSecretOperation, bytesIOOperation))
+	require.NoError(t, registry.Register(svc))
+	registry.Use(NewBodyLoggingMiddleware(BodyLoggingMiddlewareOptions{
+		Logger:      logger,
+		MaxBodySize: 64,
+		Redact: func(info HandlerInfo, value any) any {
+			switch v := value.(type) {
+			case map[string]any:
+				v["password"] = "***"
+			case secretInput:
+				v.Password = "***"
+				return v
+			}
+			return value
+		},
+		ShouldLog: func(info HandlerInfo) bool {
+			return info.Operation != bytesIOOperation.Name()
+		},
+	}))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	input := secretInput{User: "me", Password: "hunter2"}
+	result, err := ExecuteOperation(ctx, client, echoSecretOperation, input, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, input, result)
+
+	_, err = ExecuteOperation(ctx, client, bytesIOOperation, []byte("hello"), ExecuteOperationOptions{})
+	require.NoError(t, err)
+
+	// Input exceeding the size limit is not decoded.
+	input.User = strings.Repeat("a", 100)
+	result, err = ExecuteOperation(ctx, client, echoSecretOperation, input, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, input, result)
+
+	require.NotContains(t, buf.String(), "hunter2")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	require.Equal(t, "echo-secret", entry["operation"])
+	require.Equal(t, map[string]any{"user": "me", "password": "***"}, entry["input"])
+	require.Equal(t, map[string]any{"user": "me", "password": "***"}, entry["output"])
+
+	entry = nil
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	require.Equal(t, true, entry["input_truncated"])
+	require.NotContains(t, entry, "input")
+}
+
+type partialReadHandler struct {
+	UnimplementedHandler
+}
+
+func (h *partialReadHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(input.Reader, buf); err != nil {
+		return nil, err
+	}
+	return &HandlerStartOperationResultAsync{OperationID: "id"}, nil
+}
+
+func TestBodyLoggingMiddleware_PartialRead(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler, err := NewBodyLoggingMiddleware(BodyLoggingMiddlewareOptions{Logger: logger})(context.Background(), &partialReadHandler{})
+	require.NoError(t, err)
+
+	input := NewLazyValue(defaultSerializer, &Reader{
+		io.NopCloser(strings.NewReader(`{"user":"me"}`)),
+		Header{"type": "application/json"},
+	})
+	_, err = handler.StartOperation(context.Background(), testService, "partial", input, StartOperationOptions{})
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, true, entry["input_incomplete"])
+	require.NotContains(t, entry, "input")
+	require.NotContains(t, entry, "input_error")
+}
+
+type principal struct {
+	Name string
+}
+
+var principalKey = NewHandlerValueKey[*principal]("principal")
+
+type authHandler struct {
+	Handler
+}
+
+func (h *authHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	name := options.Header.Get("authorization")
+	if name == "" {
+		return nil, HandlerErrorf(HandlerErrorTypeUnauthenticated, "missing authorization")
+	}
+	ctx = WithHandlerValue(ctx, principalKey, &principal{Name: name})
+	return h.Handler.StartOperation(ctx, service, operation, input, options)
+}
+
+var whoAmIOperation = NewSyncOperation("whoami", func(ctx context.Context, input NoValue, options StartOperationOptions) (string, error) {
+	p, ok := HandlerValue(ctx, principalKey)
+	if !ok {
+		return "", HandlerErrorf(HandlerErrorTypeInternal, "missing principal")
+	}
+	info, ok := ExtractHandlerInfo(ctx)
+	if !ok {
+		return "", HandlerErrorf(HandlerErrorTypeInternal, "missing handler info")
+	}
+	return p.Name + "@" + info.Operation, nil
+})
+
+func TestHandlerValue(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(whoAmIOperation))
+	require.NoError(t, registry.Register(svc))
+	registry.Use(func(ctx context.Context, next Handler) (Handler, error) {
+		return &authHandler{Handler: next}, nil
+	})
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := ExecuteOperation(ctx, client, whoAmIOperation, nil, ExecuteOperationOptions{
+		Header: Header{"authorization": "alice"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "alice@whoami", result)
+
+	_, ok := HandlerValue(context.Background(), principalKey)
+	require.False(t, ok)
+}
+
+type adminOnlyHandler struct {
+	Handler
+}
+
+func (h *adminOnlyHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	if p, ok := HandlerValue(ctx, principalKey); !ok || p.Name != "admin" {
+		return nil, HandlerErrorf(HandlerErrorTypeUnauthorized, "admin only")
+	}
+	return h.Handler.StartOperation(ctx, service, operation, input, options)
+}
+
+func TestHandlerValue_ReadByLaterMiddleware(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(whoAmIOperation))
+	require.NoError(t, registry.Register(svc))
+	registry.Use(func(ctx context.Context, next Handler) (Handler, error) {
+		return &authHandler{Handler: next}, nil
+	})
+	registry.UseFor([]string{whoAmIOperation.Name()}, func(ctx context.Context, next Handler) (Handler, error) {
+		return &adminOnlyHandler{Handler: next}, nil
+	})
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := ExecuteOperation(ctx, client, whoAmIOperation, nil, ExecuteOperationOptions{
+		Header: Header{"authorization": "admin"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "admin@whoami", result)
+
+	_, err = ExecuteOperation(ctx, client, whoAmIOperation, nil, ExecuteOperationOptions{
+		Header: Header{"authorization": "alice"},
+	})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeUnauthorized, handlerError.Type)
+}
+
+var panickingOperation = NewSyncOperation("panic", func(ctx context.Context, input NoValue, options StartOperationOptions) (NoValue, error) {
+	panic("intentional panic")
+})
+
+func TestRecoverMiddleware(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(panickingOperation))
+	require.NoError(t, registry.Register(svc))
+	var logs bytes.Buffer
+	registry.Use(NewRecoverMiddleware(slog.New(slog.NewTextHandler(&logs, nil))))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err = ExecuteOperation(ctx, client, panickingOperation, nil, ExecuteOperationOptions{})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeInternal, handlerError.Type)
+	require.Equal(t, "internal server error", handlerError.Cause.Error())
+	require.Contains(t, logs.String(), "intentional panic")
+	require.Contains(t, logs.String(), "middleware_test.go")
+}
+
+var slowOperation = NewSyncOperation("slow", func(ctx context.Context, input time.Duration, options StartOperationOptions) (NoValue, error) {
+	select {
+	case <-time.After(input):
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+})
+
+type slowAsyncOperation struct {
+	UnimplementedOperation[time.Duration, NoValue]
+}
+
+func (*slowAsyncOperation) Name() string {
+	return "slow-async"
+}
+
+func (*slowAsyncOperation) Start(ctx context.Context, input time.Duration, options StartOperationOptions) (HandlerStartOperationResult[NoValue], error) {
+	// Ignores the context to simulate an operation that was started despite exceeding the limit.
+	time.Sleep(input)
+	return &HandlerStartOperationResultAsync{OperationID: "id"}, nil
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(slowOperation, &slowAsyncOperation{}))
+	require.NoError(t, registry.Register(svc))
+	registry.Use(NewTimeoutMiddleware(100 * time.Millisecond))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err = ExecuteOperation(ctx, client, slowOperation, time.Millisecond, ExecuteOperationOptions{})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = ExecuteOperation(ctx, client, slowOperation, time.Minute, ExecuteOperationOptions{})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeUpstreamTimeout, handlerError.Type)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	result, err := StartOperation(ctx, client, &slowAsyncOperation{}, 200*time.Millisecond, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "id", result.Pending.ID)
+}
@@ -0,0 +1,189 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const headerAccept = "Accept"
+
+// NegotiatingSerializer is a [Serializer] that selects one of a set of serializers, keyed by media type, using HTTP
+// content negotiation. This allows a single handler to respond to each client in its preferred format, e.g. JSON or
+// protobuf.
+//
+// When used by an [HTTPClient], start and get result requests carry an Accept header listing the registered media
+// types in registration order. When used by a handler created with [NewHTTPHandler], results are serialized with the
+// serializer registered for the most preferred media type in the request's Accept header, falling back to the first
+// registered serializer if the header is missing or lists no registered media type.
+//
+// Values serialized outside of a request, e.g. inputs sent by a client, use the first registered serializer. Content is
+// deserialized with the serializer registered for its media type, falling back to the first registered serializer.
+//
+// Register serializers before using the serializer. Register must not be called concurrently with serialization.
+type NegotiatingSerializer struct {
+	mediaTypes  []string
+	serializers map[string]Serializer
+}
+
+// NewNegotiatingSerializer creates a [NegotiatingSerializer] with no registered serializers.
+func NewNegotiatingSerializer() *NegotiatingSerializer {
+	return &NegotiatingSerializer{serializers: make(map[string]Serializer)}
+}
+
+// Register registers a serializer for the given media type, e.g. "application/json". The serializer should produce
+// content of that type. The first registered serializer is the default.
+//
+// Returns an error if the media type is invalid or already registered.
+func (s *NegotiatingSerializer) Register(mediaType string, serializer Serializer) error {
+	if serializer == nil {
+		return errors.New("serializer is required")
+	}
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err == nil && (!strings.Contains(parsed, "/") || strings.Contains(parsed, "*")) {
+		err = errors.New("expected type/subtype")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid media type %q: %w", mediaType, err)
+	}
+	if _, ok := s.serializers[parsed]; ok {
+		return fmt.Errorf("media type %q already registered", parsed)
+	}
+	s.mediaTypes = append(s.mediaTypes, parsed)
+	s.serializers[parsed] = serializer
+	return nil
+}
+
+// Serialize implements Serializer using the first registered serializer.
+func (s *NegotiatingSerializer) Serialize(v any) (*Content, error) {
+	if len(s.mediaTypes) == 0 {
+		return nil, errors.New("no serializers registered")
+	}
+	return s.serializers[s.mediaTypes[0]].Serialize(v)
+}
+
+// Deserialize implements Serializer using the serializer registered for the content's media type.
+func (s *NegotiatingSerializer) Deserialize(c *Content, v any) error {
+	if len(s.mediaTypes) == 0 {
+		return errors.New("no serializers registered")
+	}
+	return s.deserializer(c.Header).Deserialize(c, v)
+}
+
+// deserializer returns the serializer registered for the media type of content with the given header, falling back to
+// the first registered serializer. Must not be called if there are no registered serializers.
+func (s *NegotiatingSerializer) deserializer(header Header) Serializer {
+	if mediaType, _, err := mime.ParseMediaType(header.Get("type")); err == nil {
+		if serializer, ok := s.serializers[mediaType]; ok {
+			return serializer
+		}
+	}
+	return s.serializers[s.mediaTypes[0]]
+}
+
+// maxContentSize forwards the limit of a serializer created with [NewLimitedSerializer] registered for the content's
+// media type, for reads to be limited as if it was used directly.
+func (s *NegotiatingSerializer) maxContentSize(header Header) int64 {
+	if len(s.mediaTypes) == 0 {
+		return 0
+	}
+	if limited, ok := s.deserializer(header).(sizeLimitedSerializer); ok {
+		return limited.maxContentSize(header)
+	}
+	return 0
+}
+
+var _ Serializer = &NegotiatingSerializer{}
+var _ negotiator = &NegotiatingSerializer{}
+var _ sizeLimitedSerializer = &NegotiatingSerializer{}
+
+// negotiator is implemented by serializers that support content negotiation, allowing serializers that wrap a
+// [NegotiatingSerializer], such as the one returned by [NewLimitedSerializer], to forward negotiation to it.
+type negotiator interface {
+	// accept returns the value of the Accept header sent by clients, empty if there are no accepted media types.
+	accept() string
+	// negotiate returns the serializer to use for results given the Accept header values of a request, nil if there
+	// are no registered serializers.
+	negotiate(accept []string) Serializer
+}
+
+// accept returns the value of the Accept header sent by clients.
+func (s *NegotiatingSerializer) accept() string {
+	return strings.Join(s.mediaTypes, ", ")
+}
+
+// negotiate returns the registered serializer for the most preferred media type in the given Accept header values.
+// Media ranges with equal quality are preferred in the order they are listed.
+func (s *NegotiatingSerializer) negotiate(accept []string) Serializer {
+	var best Serializer
+	bestQuality := 0.0
+	for _, value := range accept {
+		for _, mediaRange := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			if quality <= bestQuality {
+				continue
+			}
+			if serializer := s.match(mediaType); serializer != nil {
+				best, bestQuality = serializer, quality
+			}
+		}
+	}
+	if best == nil && len(s.mediaTypes) > 0 {
+		return s.serializers[s.mediaTypes[0]]
+	}
+	return best
+}
+
+// match returns the first registered serializer matching a media range, which may be a wildcard such as "*/*" or
+// "application/*".
+func (s *NegotiatingSerializer) match(mediaRange string) Serializer {
+	if mediaRange == "*/*" {
+		return s.serializers[s.mediaTypes[0]]
+	}
+	if serializer, ok := s.serializers[mediaRange]; ok {
+		return serializer
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "*")
+	if !ok {
+		return nil
+	}
+	for _, mediaType := range s.mediaTypes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return s.serializers[mediaType]
+		}
+	}
+	return nil
+}
+
+// addAcceptToHTTPHeader lists the media types accepted by the given serializer in the Accept header, if it supports
+// content negotiation.
+func addAcceptToHTTPHeader(serializer Serializer, header http.Header) {
+	if s, ok := serializer.(negotiator); ok {
+		if accept := s.accept(); accept != "" {
+			header.Set(headerAccept, accept)
+		}
+	}
+}
+
+// negotiateSerializer returns the serializer to use for results written in response to a request with the given
+// header, see [NegotiatingSerializer].
+func negotiateSerializer(serializer Serializer, header http.Header) Serializer {
+	if s, ok := serializer.(negotiator); ok {
+		if negotiated := s.negotiate(header.Values(headerAccept)); negotiated != nil {
+			return negotiated
+		}
+	}
+	return serializer
+}
@@ -0,0 +1,147 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type textSerializer struct{}
+
+func (textSerializer) Serialize(v any) (*Content, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected string, got %T", errSerializerIncompatible, v)
+	}
+	return &Content{Header: Header{"type": "text/plain"}, Data: []byte(s)}, nil
+}
+
+func (textSerializer) Deserialize(c *Content, v any) error {
+	s, ok := v.(*string)
+	if !ok {
+		return fmt.Errorf("%w: expected *string, got %T", errSerializerIncompatible, v)
+	}
+	*s = string(c.Data)
+	return nil
+}
+
+func newTestNegotiatingSerializer(t *testing.T, mediaTypes ...string) *NegotiatingSerializer {
+	s := NewNegotiatingSerializer()
+	for _, mediaType := range mediaTypes {
+		var serializer Serializer = textSerializer{}
+		if mediaType == "application/json" {
+			serializer = DefaultSerializer()
+		}
+		require.NoError(t, s.Register(mediaType, serializer))
+	}
+	return s
+}
+
+type greetingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *greetingHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	if operation == "async" {
+		return &HandlerStartOperationResultAsync{OperationID: "id"}, nil
+	}
+	return &HandlerStartOperationResultSync[any]{Value: "hello"}, nil
+}
+
+func (h *greetingHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	return "hello", nil
+}
+
+func TestNegotiatingSerializer(t *testing.T) {
+	handlerSerializer := newTestNegotiatingSerializer(t, "application/json", "text/plain")
+	ctx, client, teardown := setupCustom(t, &greetingHandler{}, handlerSerializer, nil)
+	defer teardown()
+
+	cases := []struct {
+		name        string
+		serializer  Serializer
+		contentType string
+	}{
+		{"no accept header", DefaultSerializer(), "application/json"},
+		{"prefers json", newTestNegotiatingSerializer(t, "application/json", "text/plain"), "application/json"},
+		{"prefers text", newTestNegotiatingSerializer(t, "text/plain", "application/json"), "text/plain"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client.options.Serializer = c.serializer
+
+			result, err := client.StartOperation(ctx, "sync", "hi", StartOperationOptions{})
+			require.NoError(t, err)
+			require.Equal(t, c.contentType, result.Successful.Reader.Header.Get("type"))
+			var output string
+			require.NoError(t, result.Successful.Consume(&output))
+			require.Equal(t, "hello", output)
+
+			result, err = client.StartOperation(ctx, "async", "hi", StartOperationOptions{})
+			require.NoError(t, err)
+			value, err := result.Pending.GetResult(ctx, GetOperationResultOptions{})
+			require.NoError(t, err)
+			require.Equal(t, c.contentType, value.Reader.Header.Get("type"))
+			require.NoError(t, value.Consume(&output))
+			require.Equal(t, "hello", output)
+		})
+	}
+}
+
+func TestNegotiatingSerializer_Limited(t *testing.T) {
+	handlerSerializer := NewLimitedSerializer(newTestNegotiatingSerializer(t, "application/json", "text/plain"), 1024)
+	ctx, client, teardown := setupCustom(t, &greetingHandler{}, handlerSerializer, nil)
+	defer teardown()
+	client.options.Serializer = NewLimitedSerializer(newTestNegotiatingSerializer(t, "text/plain", "application/json"), 1024)
+
+	result, err := client.StartOperation(ctx, "sync", "hi", StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "text/plain", result.Successful.Reader.Header.Get("type"))
+	var output string
+	require.NoError(t, result.Successful.Consume(&output))
+	require.Equal(t, "hello", output)
+
+	client.options.Serializer = NewLimitedSerializer(newTestNegotiatingSerializer(t, "text/plain", "application/json"), 2)
+	result, err = client.StartOperation(ctx, "sync", "hi", StartOperationOptions{})
+	require.NoError(t, err)
+	require.ErrorIs(t, result.Successful.Consume(&output), ErrContentTooLarge)
+}
+
+func TestNegotiatingSerializer_RegisteredLimited(t *testing.T) {
+	s := NewNegotiatingSerializer()
+	require.NoError(t, s.Register("application/json", NewLimitedSerializer(DefaultSerializer(), 16)))
+	require.NoError(t, s.Register("text/plain", textSerializer{}))
+
+	// Reads of content of a limited media type stop once the limit is exceeded.
+	var v string
+	reader := &countingReader{Reader: strings.NewReader(`"` + strings.Repeat("a", 1024*1024) + `"`)}
+	value := NewLazyValue(s, &Reader{io.NopCloser(reader), Header{"type": "application/json"}})
+	require.ErrorIs(t, value.Consume(&v), ErrContentTooLarge)
+	require.Equal(t, 17, reader.read)
+
+	large := strings.Repeat("a", 1024)
+	value = NewLazyValue(s, &Reader{io.NopCloser(strings.NewReader(large)), Header{"type": "text/plain"}})
+	require.NoError(t, value.Consume(&v))
+	require.Equal(t, large, v)
+}
+
+func TestNegotiatingSerializer_Negotiate(t *testing.T) {
+	s := newTestNegotiatingSerializer(t, "application/json", "text/plain")
+	json := s.serializers["application/json"]
+	text := s.serializers["text/plain"]
+
+	require.Equal(t, json, s.negotiate(nil))
+	require.Equal(t, json, s.negotiate([]string{"image/png"}))
+	require.Equal(t, json, s.negotiate([]string{"*/*"}))
+	require.Equal(t, text, s.negotiate([]string{"text/*"}))
+	require.Equal(t, text, s.negotiate([]string{"application/json;q=0.5, text/plain"}))
+	require.Equal(t, text, s.negotiate([]string{"image/png", "text/plain, application/json"}))
+	require.Equal(t, json, s.negotiate([]string{"text/plain;q=0, application/json;q=0.1"}))
+
+	require.ErrorContains(t, s.Register("text/plain; charset=utf-8", textSerializer{}), `media type "text/plain" already registered`)
+	require.ErrorContains(t, s.Register("invalid", textSerializer{}), `invalid media type "invalid"`)
+}
@@ -0,0 +1,225 @@
+// Package nexustest provides utilities for testing Nexus handlers, end-to-end over HTTP or in-process.
+package nexustest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// DefaultService is the service name used by a [TestHandler] and by clients returned from [NewServer] when no service
+// is set in the options.
+const DefaultService = "test-service"
+
+// TestHandlerOptions are options for [NewTestHandler].
+type TestHandlerOptions struct {
+	// Name of the service operations are invoked on. Defaults to [DefaultService].
+	Service string
+	// A [nexus.Serializer] used to encode inputs and decode results. Defaults to the SDK's default serializer.
+	Serializer nexus.Serializer
+}
+
+// TestHandler invokes the methods of a [nexus.Handler] in-process, without HTTP, for unit testing operations. Inputs
+// and results are passed through the configured serializer, as they would be over the wire, surfacing serialization
+// issues. Errors returned by the handler, such as [*nexus.HandlerError] and [*nexus.UnsuccessfulOperationError], are
+// returned as is. As with an HTTP handler, dry run requests are only dispatched to a [nexus.DryRunHandler].
+//
+//	handler, _ := registry.NewHandler()
+//	h := nexustest.NewTestHandler(handler, nexustest.TestHandlerOptions{Service: "my-service"})
+//	result, err := nexustest.StartOperation(ctx, h, myOperation, MyInput{}, nexus.StartOperationOptions{})
+//	output := nexustest.RequireSync(t, result, err)
+type TestHandler struct {
+	handler nexus.Handler
+	options TestHandlerOptions
+}
+
+// NewTestHandler creates a [TestHandler] for the given handler, typically created with [nexus.ServiceRegistry.NewHandler].
+func NewTestHandler(handler nexus.Handler, options TestHandlerOptions) *TestHandler {
+	if options.Service == "" {
+		options.Service = DefaultService
+	}
+	if options.Serializer == nil {
+		options.Serializer = nexus.DefaultSerializer()
+	}
+	return &TestHandler{handler: handler, options: options}
+}
+
+// StartResult is the outcome of a successful start request made with [StartOperation].
+type StartResult[O any] struct {
+	// Set if the operation completed synchronously.
+	Sync bool
+	// Output of an operation that completed synchronously.
+	Output O
+	// ID of an operation started asynchronously.
+	OperationID string
+	// Set if a dry run request passed validation, see [nexus.StartOperationOptions.DryRun].
+	DryRun bool
+	// Links attached to the result by the handler.
+	Links []nexus.Link
+}
+
+// StartOperation starts an operation on the handler, encoding input with the handler's serializer. The output of
+// operations that complete synchronously is decoded into O.
+func StartOperation[I, O any](ctx context.Context, h *TestHandler, operation nexus.OperationReference[I, O], input I, options nexus.StartOperationOptions) (*StartResult[O], error) {
+	content, err := h.options.Serializer.Serialize(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize input: %w", err)
+	}
+	inputValue := nexus.NewLazyValue(h.options.Serializer, &nexus.Reader{
+		ReadCloser: io.NopCloser(bytes.NewReader(content.Data)),
+		Header:     content.Header,
+	})
+	if options.Header == nil {
+		options.Header = nexus.Header{}
+	}
+	if options.RequestID == "" {
+		options.RequestID = uuid.NewString()
+	}
+	if options.DryRun {
+		if d, ok := h.handler.(nexus.DryRunHandler); !ok || !d.SupportsDryRun(h.options.Service, operation.Name()) {
+			return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeNotImplemented, "operation %q does not support dry run", operation.Name())
+		}
+	}
+	result, err := h.handler.StartOperation(ctx, h.options.Service, operation.Name(), inputValue, options)
+	if err != nil {
+		return nil, err
+	}
+	switch r := result.(type) {
+	case *nexus.HandlerStartOperationResultDryRun:
+		return &StartResult[O]{DryRun: true}, nil
+	case *nexus.HandlerStartOperationResultAsync:
+		return &StartResult[O]{OperationID: r.OperationID, Links: r.Links}, nil
+	case nexus.SyncStartOperationResult:
+		// Operations registered with a [nexus.ServiceRegistry] return a [nexus.HandlerStartOperationResultSync] of
+		// their output type rather than of any.
+		output, err := decode[O](h.options.Serializer, r.ResultValue())
+		if err != nil {
+			return nil, err
+		}
+		return &StartResult[O]{Sync: true, Output: output, Links: r.ResultLinks()}, nil
+	default:
+		return nil, fmt.Errorf("unexpected start operation result type: %T", result)
+	}
+}
+
+// GetOperationResult gets the result of an operation from the handler and decodes it into O.
+func GetOperationResult[I, O any](ctx context.Context, h *TestHandler, operation nexus.OperationReference[I, O], operationID string, options nexus.GetOperationResultOptions) (O, error) {
+	if options.Header == nil {
+		options.Header = nexus.Header{}
+	}
+	result, err := h.handler.GetOperationResult(ctx, h.options.Service, operation.Name(), operationID, options)
+	if err != nil {
+		var o O
+		return o, err
+	}
+	return decode[O](h.options.Serializer, result)
+}
+
+// GetOperationInfo gets information on an operation from the handler.
+func (h *TestHandler) GetOperationInfo(ctx context.Context, operation, operationID string, options nexus.GetOperationInfoOptions) (*nexus.OperationInfo, error) {
+	if options.Header == nil {
+		options.Header = nexus.Header{}
+	}
+	return h.handler.GetOperationInfo(ctx, h.options.Service, operation, operationID, options)
+}
+
+// CancelOperation requests cancelation of an operation from the handler.
+func (h *TestHandler) CancelOperation(ctx context.Context, operation, operationID string, options nexus.CancelOperationOptions) (*nexus.CancelOperationResult, error) {
+	if options.Header == nil {
+		options.Header = nexus.Header{}
+	}
+	return h.handler.CancelOperation(ctx, h.options.Service, operation, operationID, options)
+}
+
+// decode round trips a handler result through the serializer into an O. Results provided as a [*nexus.Content] or
+// [*nexus.Reader] are deserialized as is.
+func decode[O any](serializer nexus.Serializer, value any) (O, error) {
+	var o O
+	var content *nexus.Content
+	switch v := value.(type) {
+	case *nexus.Content:
+		content = v
+	case *nexus.Reader:
+		content = &nexus.Content{Header: v.Header}
+		if v.ReadCloser != nil {
+			defer v.Close()
+			data, err := io.ReadAll(v)
+			if err != nil {
+				return o, fmt.Errorf("failed to read result: %w", err)
+			}
+			content.Data = data
+		}
+	default:
+		var err error
+		if content, err = serializer.Serialize(value); err != nil {
+			return o, fmt.Errorf("failed to serialize result: %w", err)
+		}
+	}
+	if err := serializer.Deserialize(content, &o); err != nil {
+		return o, fmt.Errorf("failed to deserialize result: %w", err)
+	}
+	return o, nil
+}
+
+// RequireSync fails the test unless a start request completed synchronously and successfully, and returns its output.
+func RequireSync[O any](t testing.TB, result *StartResult[O], err error) O {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("nexustest: expected operation to complete synchronously, got error: %v", err)
+	}
+	if result.DryRun {
+		t.Fatalf("nexustest: expected operation to complete synchronously, got dry run result")
+	}
+	if !result.Sync {
+		t.Fatalf("nexustest: expected operation to complete synchronously, got operation ID %q", result.OperationID)
+	}
+	return result.Output
+}
+
+// RequireAsync fails the test unless a start request started an asynchronous operation, and returns its ID.
+func RequireAsync[O any](t testing.TB, result *StartResult[O], err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("nexustest: expected operation to start asynchronously, got error: %v", err)
+	}
+	if result.Sync {
+		t.Fatalf("nexustest: expected operation to start asynchronously, completed synchronously")
+	}
+	if result.DryRun {
+		t.Fatalf("nexustest: expected operation to start asynchronously, got dry run result")
+	}
+	return result.OperationID
+}
+
+// RequireHandlerError fails the test unless err is a [*nexus.HandlerError] of the given type, and returns it.
+func RequireHandlerError(t testing.TB, err error, errorType nexus.HandlerErrorType) *nexus.HandlerError {
+	t.Helper()
+	var handlerError *nexus.HandlerError
+	if !errors.As(err, &handlerError) {
+		t.Fatalf("nexustest: expected handler error of type %s, got: %v", errorType, err)
+	}
+	if handlerError.Type != errorType {
+		t.Fatalf("nexustest: expected handler error of type %s, got type %s: %v", errorType, handlerError.Type, err)
+	}
+	return handlerError
+}
+
+// RequireUnsuccessful fails the test unless err is a [*nexus.UnsuccessfulOperationError] with the given state, and
+// returns it.
+func RequireUnsuccessful(t testing.TB, err error, state nexus.OperationState) *nexus.UnsuccessfulOperationError {
+	t.Helper()
+	var unsuccessfulOperationError *nexus.UnsuccessfulOperationError
+	if !errors.As(err, &unsuccessfulOperationError) {
+		t.Fatalf("nexustest: expected unsuccessful operation error with state %s, got: %v", state, err)
+	}
+	if unsuccessfulOperationError.State != state {
+		t.Fatalf("nexustest: expected unsuccessful operation error with state %s, got state %s: %v", state, unsuccessfulOperationError.State, err)
+	}
+	return unsuccessfulOperationError
+}
@@ -0,0 +1,115 @@
+package nexustest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/nexus-rpc/sdk-go/nexus/nexustest"
+	"github.com/stretchr/testify/require"
+)
+
+var greetOperation = nexus.NewSyncOperation("greet", func(ctx context.Context, name string, options nexus.StartOperationOptions) (string, error) {
+	return "hello " + name, nil
+})
+
+type counterInput struct {
+	Count int
+}
+
+type asyncCounterOperation struct {
+	nexus.UnimplementedOperation[counterInput, int]
+}
+
+func (*asyncCounterOperation) Name() string {
+	return "counter"
+}
+
+func (*asyncCounterOperation) Start(ctx context.Context, input counterInput, options nexus.StartOperationOptions) (nexus.HandlerStartOperationResult[int], error) {
+	if input.Count < 0 {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "count must not be negative")
+	}
+	return &nexus.HandlerStartOperationResultAsync{OperationID: "counter-id"}, nil
+}
+
+func (*asyncCounterOperation) ValidateInput(ctx context.Context, input counterInput, options nexus.StartOperationOptions) error {
+	if input.Count < 0 {
+		return nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "count must not be negative")
+	}
+	return nil
+}
+
+func (*asyncCounterOperation) GetResult(ctx context.Context, id string, options nexus.GetOperationResultOptions) (int, error) {
+	if id != "counter-id" {
+		return 0, nexus.HandlerErrorf(nexus.HandlerErrorTypeNotFound, "operation not found")
+	}
+	return 3, nil
+}
+
+func (*asyncCounterOperation) Cancel(ctx context.Context, id string, options nexus.CancelOperationOptions) (*nexus.CancelOperationResult, error) {
+	return nil, &nexus.UnsuccessfulOperationError{State: nexus.OperationStateCanceled, Cause: &nexus.FailureError{}}
+}
+
+func newTestHandler(t *testing.T) *nexustest.TestHandler {
+	svc := nexus.NewService("greeter")
+	require.NoError(t, svc.Register(greetOperation, &asyncCounterOperation{}))
+	registry := nexus.NewServiceRegistry()
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+	return nexustest.NewTestHandler(handler, nexustest.TestHandlerOptions{Service: "greeter"})
+}
+
+func TestTestHandler_Sync(t *testing.T) {
+	h := newTestHandler(t)
+	result, err := nexustest.StartOperation(context.Background(), h, greetOperation, "nexus", nexus.StartOperationOptions{})
+	require.Equal(t, "hello nexus", nexustest.RequireSync(t, result, err))
+}
+
+func TestTestHandler_Async(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+	ref := nexus.NewOperationReference[counterInput, int]("counter")
+
+	result, err := nexustest.StartOperation(ctx, h, ref, counterInput{Count: 3}, nexus.StartOperationOptions{})
+	id := nexustest.RequireAsync(t, result, err)
+	require.Equal(t, "counter-id", id)
+
+	output, err := nexustest.GetOperationResult(ctx, h, ref, id, nexus.GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, output)
+
+	_, err = nexustest.GetOperationResult(ctx, h, ref, "unknown", nexus.GetOperationResultOptions{})
+	nexustest.RequireHandlerError(t, err, nexus.HandlerErrorTypeNotFound)
+
+	_, err = h.CancelOperation(ctx, "counter", id, nexus.CancelOperationOptions{})
+	nexustest.RequireUnsuccessful(t, err, nexus.OperationStateCanceled)
+}
+
+func TestTestHandler_Errors(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+
+	_, err := nexustest.StartOperation(ctx, h, nexus.NewOperationReference[counterInput, int]("counter"), counterInput{Count: -1}, nexus.StartOperationOptions{})
+	handlerErr := nexustest.RequireHandlerError(t, err, nexus.HandlerErrorTypeBadRequest)
+	require.ErrorContains(t, handlerErr, "count must not be negative")
+
+	_, err = nexustest.StartOperation(ctx, h, nexus.NewOperationReference[string, string]("unknown"), "", nexus.StartOperationOptions{})
+	nexustest.RequireHandlerError(t, err, nexus.HandlerErrorTypeNotFound)
+}
+
+func TestTestHandler_DryRun(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+	ref := nexus.NewOperationReference[counterInput, int]("counter")
+
+	result, err := nexustest.StartOperation(ctx, h, ref, counterInput{Count: 3}, nexus.StartOperationOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, &nexustest.StartResult[int]{DryRun: true}, result)
+
+	_, err = nexustest.StartOperation(ctx, h, ref, counterInput{Count: -1}, nexus.StartOperationOptions{DryRun: true})
+	nexustest.RequireHandlerError(t, err, nexus.HandlerErrorTypeBadRequest)
+
+	_, err = nexustest.StartOperation(ctx, h, greetOperation, "nexus", nexus.StartOperationOptions{DryRun: true})
+	nexustest.RequireHandlerError(t, err, nexus.HandlerErrorTypeNotImplemented)
+}
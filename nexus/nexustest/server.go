@@ -0,0 +1,99 @@
+package nexustest
+
+import (
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+)
+
+// ServerOptions are options for [NewServer] and [NewServiceServer].
+type ServerOptions struct {
+	// Name of the service the returned client targets. Defaults to [DefaultService].
+	Service string
+	// A [nexus.Serializer] used by both the handler and the client. Defaults to the SDK's default serializer.
+	Serializer nexus.Serializer
+	// A [nexus.FailureConverter] used by both the handler and the client. Defaults to
+	// [nexus.DefaultFailureConverter].
+	FailureConverter nexus.FailureConverter
+	// A structured logger for the handler. Defaults to slog.Default().
+	Logger *slog.Logger
+	// Middleware applied to operation invocations with [nexus.ServiceRegistry.Use]. Only supported by
+	// [NewServiceServer], [NewServer] fails the test if set.
+	Middleware []nexus.MiddlewareFunc
+}
+
+// NewServer starts an [httptest.Server] serving handler with [nexus.NewHTTPHandler], and returns an
+// [nexus.HTTPClient] for the configured service pointing at it, along with a function to shut the server down.
+//
+//	client, teardown := nexustest.NewServer(t, handler, nexustest.ServerOptions{})
+//	defer teardown()
+//	result, err := nexus.ExecuteOperation(ctx, client, myOperation, input, nexus.ExecuteOperationOptions{})
+//
+// The server is also shut down when the test completes, calling teardown is only needed to shut it down earlier.
+func NewServer(t testing.TB, handler nexus.Handler, options ServerOptions) (client *nexus.HTTPClient, teardown func()) {
+	t.Helper()
+	if len(options.Middleware) > 0 {
+		t.Fatal("nexustest: middleware is only supported by NewServiceServer")
+	}
+	if options.Service == "" {
+		options.Service = DefaultService
+	}
+	server := httptest.NewServer(nexus.NewHTTPHandler(nexus.HandlerOptions{
+		Handler:          handler,
+		Logger:           options.Logger,
+		Serializer:       options.Serializer,
+		FailureConverter: options.FailureConverter,
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := nexus.NewHTTPClient(nexus.HTTPClientOptions{
+		BaseURL:          server.URL,
+		Service:          options.Service,
+		HTTPCaller:       server.Client().Do,
+		Serializer:       options.Serializer,
+		FailureConverter: options.FailureConverter,
+	})
+	if err != nil {
+		server.Close()
+		t.Fatalf("nexustest: failed to create client: %v", err)
+	}
+	return client, server.Close
+}
+
+// NewServiceServer is like [NewServer] but serves the given services with a handler created by a
+// [nexus.ServiceRegistry], applying ServerOptions.Middleware. The client targets ServerOptions.Service, which defaults to
+// the name of the first service.
+//
+// The registry's handler is closed when the server is shut down.
+func NewServiceServer(t testing.TB, options ServerOptions, services ...*nexus.Service) (client *nexus.HTTPClient, teardown func()) {
+	t.Helper()
+	registry := nexus.NewServiceRegistry()
+	if err := registry.Register(services...); err != nil {
+		t.Fatalf("nexustest: failed to register services: %v", err)
+	}
+	registry.Use(options.Middleware...)
+	handler, err := registry.NewHandler()
+	if err != nil {
+		t.Fatalf("nexustest: failed to create handler: %v", err)
+	}
+	if options.Service == "" {
+		options.Service = services[0].Name
+	}
+	options.Middleware = nil
+	client, closeServer := NewServer(t, handler, options)
+	var once sync.Once
+	teardown = func() {
+		once.Do(func() {
+			closeServer()
+			if err := handler.Close(context.Background()); err != nil {
+				t.Errorf("nexustest: failed to close handler: %v", err)
+			}
+		})
+	}
+	t.Cleanup(teardown)
+	return client, teardown
+}
@@ -0,0 +1,49 @@
+package nexustest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/nexus-rpc/sdk-go/nexus/nexustest"
+	"github.com/stretchr/testify/require"
+)
+
+type asyncHandler struct {
+	nexus.UnimplementedHandler
+}
+
+func (h *asyncHandler) StartOperation(ctx context.Context, service, operation string, input *nexus.LazyValue, options nexus.StartOperationOptions) (nexus.HandlerStartOperationResult[any], error) {
+	if service != nexustest.DefaultService {
+		return nil, nexus.HandlerErrorf(nexus.HandlerErrorTypeBadRequest, "unexpected service: %s", service)
+	}
+	return &nexus.HandlerStartOperationResultAsync{OperationID: "id"}, nil
+}
+
+func TestNewServer(t *testing.T) {
+	client, teardown := nexustest.NewServer(t, &asyncHandler{}, nexustest.ServerOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(context.Background(), "op", nil, nexus.StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "id", result.Pending.ID)
+}
+
+func TestNewServiceServer(t *testing.T) {
+	svc := nexus.NewService("greeter")
+	require.NoError(t, svc.Register(greetOperation))
+	var invoked []string
+	client, teardown := nexustest.NewServiceServer(t, nexustest.ServerOptions{
+		Middleware: []nexus.MiddlewareFunc{func(ctx context.Context, next nexus.Handler) (nexus.Handler, error) {
+			info, _ := nexus.ExtractHandlerInfo(ctx)
+			invoked = append(invoked, info.Service+"/"+info.Operation)
+			return next, nil
+		}},
+	}, svc)
+	defer teardown()
+
+	output, err := nexus.ExecuteOperation(context.Background(), client, greetOperation, "nexus", nexus.ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "hello nexus", output)
+	require.Equal(t, []string{"greeter/greet"}, invoked)
+}
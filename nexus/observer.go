@@ -0,0 +1,85 @@
+package nexus
+
+import (
+	"errors"
+	"time"
+)
+
+// OutcomeKind classifies the outcome of a client call reported to a [ClientObserver].
+type OutcomeKind string
+
+const (
+	// The call succeeded. For start calls, the operation completed synchronously.
+	OutcomeSucceeded OutcomeKind = "succeeded"
+	// The operation is still running. For start calls, the operation was started asynchronously. For get result calls,
+	// the result was not ready before the wait period exceeded.
+	OutcomePending OutcomeKind = "pending"
+	// The operation completed unsuccessfully, see [UnsuccessfulOperationError]. Outcome.OperationState holds the
+	// operation's state.
+	OutcomeUnsuccessful OutcomeKind = "unsuccessful"
+	// The handler rejected the operation without starting it, see [OperationRejectedError].
+	OutcomeRejected OutcomeKind = "rejected"
+	// The handler responded with a [HandlerError]. Outcome.HandlerErrorType holds the error's type.
+	OutcomeHandlerError OutcomeKind = "handler_error"
+	// The call failed for any other reason, e.g. a network error, an unexpected response or a canceled context.
+	OutcomeTransportError OutcomeKind = "transport_error"
+)
+
+// Outcome of a client call reported to a [ClientObserver].
+type Outcome struct {
+	// Kind of outcome.
+	Kind OutcomeKind
+	// State of an unsuccessful operation, set for OutcomeUnsuccessful.
+	OperationState OperationState
+	// Type of the handler error, set for OutcomeHandlerError.
+	HandlerErrorType HandlerErrorType
+	// The error returned by the call, nil for OutcomeSucceeded.
+	Err error
+}
+
+// ClientObserver is notified when client calls complete, to instrument their latency and outcome without wrapping every
+// call site, see [HTTPClientOptions.Observer].
+//
+// Each method is called once per [HTTPClient] or [OperationHandle] call, including calls that return without issuing a
+// request, with the service and operation names and the duration of the call. A long polling get result call is
+// reported once, regardless of the number of requests issued. Calls made by [HTTPClient.ExecuteOperation] are reported
+// individually.
+//
+// Methods are called synchronously and must be safe for concurrent use.
+type ClientObserver interface {
+	StartOperationDone(service, operation string, outcome Outcome, duration time.Duration)
+	GetOperationInfoDone(service, operation string, outcome Outcome, duration time.Duration)
+	GetOperationResultDone(service, operation string, outcome Outcome, duration time.Duration)
+	CancelOperationDone(service, operation string, outcome Outcome, duration time.Duration)
+	RegisterOperationCallbackDone(service, operation string, outcome Outcome, duration time.Duration)
+}
+
+// observe reports the outcome of a call that started at start to the client's observer, if set.
+func (c *HTTPClient) observe(done func(ClientObserver, string, string, Outcome, time.Duration), operation string, start time.Time, outcome Outcome) {
+	if c.options.Observer == nil {
+		return
+	}
+	done(c.options.Observer, c.options.Service, operation, outcome, time.Since(start))
+}
+
+// outcomeFromError classifies the error returned by a client call.
+func outcomeFromError(err error) Outcome {
+	if err == nil {
+		return Outcome{Kind: OutcomeSucceeded}
+	}
+	var unsuccessfulOperationError *UnsuccessfulOperationError
+	var rejectedError *OperationRejectedError
+	var handlerError *HandlerError
+	switch {
+	case errors.Is(err, ErrOperationStillRunning):
+		return Outcome{Kind: OutcomePending, Err: err}
+	case errors.As(err, &unsuccessfulOperationError):
+		return Outcome{Kind: OutcomeUnsuccessful, OperationState: unsuccessfulOperationError.State, Err: err}
+	case errors.As(err, &rejectedError):
+		return Outcome{Kind: OutcomeRejected, Err: err}
+	case errors.As(err, &handlerError):
+		return Outcome{Kind: OutcomeHandlerError, HandlerErrorType: handlerError.Type, Err: err}
+	default:
+		return Outcome{Kind: OutcomeTransportError, Err: err}
+	}
+}
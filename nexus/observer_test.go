@@ -0,0 +1,115 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type observedCall struct {
+	method    string
+	service   string
+	operation string
+	outcome   Outcome
+}
+
+type recordingObserver struct {
+	mu    sync.Mutex
+	calls []observedCall
+}
+
+func (o *recordingObserver) record(method, service, operation string, outcome Outcome) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	outcome.Err = nil
+	o.calls = append(o.calls, observedCall{method, service, operation, outcome})
+}
+
+func (o *recordingObserver) StartOperationDone(service, operation string, outcome Outcome, duration time.Duration) {
+	o.record("start", service, operation, outcome)
+}
+
+func (o *recordingObserver) GetOperationInfoDone(service, operation string, outcome Outcome, duration time.Duration) {
+	o.record("info", service, operation, outcome)
+}
+
+func (o *recordingObserver) GetOperationResultDone(service, operation string, outcome Outcome, duration time.Duration) {
+	o.record("result", service, operation, outcome)
+}
+
+func (o *recordingObserver) CancelOperationDone(service, operation string, outcome Outcome, duration time.Duration) {
+	o.record("cancel", service, operation, outcome)
+}
+
+func (o *recordingObserver) RegisterOperationCallbackDone(service, operation string, outcome Outcome, duration time.Duration) {
+	o.record("callback", service, operation, outcome)
+}
+
+func (o *recordingObserver) last() observedCall {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.calls[len(o.calls)-1]
+}
+
+type outcomeHandler struct {
+	UnimplementedHandler
+}
+
+func (h *outcomeHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	switch operation {
+	case "sync":
+		return &HandlerStartOperationResultSync[any]{Value: "ok"}, nil
+	case "async":
+		return &HandlerStartOperationResultAsync{OperationID: "id"}, nil
+	case "failed":
+		return nil, NewFailedOperationError(errors.New("failed"))
+	default:
+		return nil, HandlerErrorf(HandlerErrorTypeUnavailable, "unavailable")
+	}
+}
+
+func (h *outcomeHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	return nil, ErrOperationStillRunning
+}
+
+func TestClientObserver(t *testing.T) {
+	ctx, client, teardown := setup(t, &outcomeHandler{})
+	defer teardown()
+	observer := &recordingObserver{}
+	client.options.Observer = observer
+
+	result, err := client.StartOperation(ctx, "sync", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.NoError(t, result.Successful.Consume(new(string)))
+	require.Equal(t, observedCall{"start", testService, "sync", Outcome{Kind: OutcomeSucceeded}}, observer.last())
+
+	result, err = client.StartOperation(ctx, "async", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, observedCall{"start", testService, "async", Outcome{Kind: OutcomePending}}, observer.last())
+
+	_, err = result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.Equal(t, observedCall{"result", testService, "async", Outcome{Kind: OutcomePending}}, observer.last())
+
+	_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.Error(t, err)
+	require.Equal(t, observedCall{"info", testService, "async", Outcome{Kind: OutcomeHandlerError, HandlerErrorType: HandlerErrorTypeNotImplemented}}, observer.last())
+
+	_, err = client.StartOperation(ctx, "failed", nil, StartOperationOptions{})
+	require.Error(t, err)
+	require.Equal(t, observedCall{"start", testService, "failed", Outcome{Kind: OutcomeUnsuccessful, OperationState: OperationStateFailed}}, observer.last())
+
+	_, err = client.StartOperation(ctx, "unavailable", nil, StartOperationOptions{})
+	require.Error(t, err)
+	require.Equal(t, observedCall{"start", testService, "unavailable", Outcome{Kind: OutcomeHandlerError, HandlerErrorType: HandlerErrorTypeUnavailable}}, observer.last())
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.StartOperation(canceledCtx, "sync", nil, StartOperationOptions{})
+	require.Error(t, err)
+	require.Equal(t, observedCall{"start", testService, "sync", Outcome{Kind: OutcomeTransportError}}, observer.last())
+}
@@ -0,0 +1,294 @@
+package nexus
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPISpec generates an OpenAPI 3 document in JSON format describing the Nexus HTTP endpoints of all registered
+// operations: start, get result, get info, and cancel.
+//
+// Request and response schemas are derived from the operations' InputType and OutputType, see [OperationReference],
+// assuming the default [Serializer]. [NoValue] maps to an empty body, []byte to application/octet-stream content, and
+// other types to application/json content. Types without a known JSON representation, such as interfaces or types that
+// implement [json.Marshaler], are described as a generic object.
+func (r *ServiceRegistry) OpenAPISpec() ([]byte, error) {
+	g := &openAPIGenerator{schemas: map[string]any{
+		"OperationInfo": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"id":    map[string]any{"type": "string"},
+				"state": map[string]any{"type": "string", "enum": []string{"running", "succeeded", "failed", "canceled"}},
+			},
+			"required": []string{"id", "state"},
+		},
+		"Failure": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"message":  map[string]any{"type": "string"},
+				"metadata": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+				"details":  map[string]any{},
+				"cause":    schemaRef("Failure"),
+			},
+			"required": []string{"message"},
+		},
+	}}
+	paths := make(map[string]any)
+	for _, serviceName := range sortedKeys(r.services) {
+		operations := r.services[serviceName].snapshot()
+		for _, operationName := range sortedKeys(operations) {
+			g.addOperationPaths(paths, serviceName, operations[operationName])
+		}
+	}
+	return json.MarshalIndent(map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Nexus Services",
+			"version": version,
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": g.schemas},
+	}, "", "  ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+type openAPIGenerator struct {
+	// Component schemas by name, populated with named struct types as they are encountered.
+	schemas map[string]any
+}
+
+var (
+	noValueType         = reflect.TypeOf(NoValue(nil))
+	byteSliceType       = reflect.TypeOf([]byte(nil))
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	invalidSchemaKeyRE  = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+	failureResponse     = map[string]any{"description": "Operation failed or canceled", "content": jsonContent(schemaRef("Failure"))}
+	errorResponse       = map[string]any{"description": "Handler error", "content": jsonContent(schemaRef("Failure"))}
+	operationIDParam    = map[string]any{"name": "operationId", "in": "path", "required": true, "schema": map[string]any{"type": "string"}}
+	operationInfoResult = map[string]any{"description": "Operation information", "content": jsonContent(schemaRef("OperationInfo"))}
+)
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{contentTypeJSON: map[string]any{"schema": schema}}
+}
+
+func (g *openAPIGenerator) addOperationPaths(paths map[string]any, service string, op RegisterableOperation) {
+	var inputType, outputType reflect.Type
+	if typed, ok := op.(interface {
+		InputType() reflect.Type
+		OutputType() reflect.Type
+	}); ok {
+		inputType, outputType = typed.InputType(), typed.OutputType()
+	}
+	name := op.Name()
+	basePath := "/" + url.PathEscape(service) + "/" + url.PathEscape(name)
+	operationID := func(action string) string {
+		return service + "." + name + "." + action
+	}
+	result := g.resultResponse(outputType)
+
+	start := map[string]any{
+		"operationId": operationID("start"),
+		"summary":     "Start " + name,
+		"tags":        []string{service},
+		"parameters": []any{
+			map[string]any{"name": queryCallbackURL, "in": "query", "schema": map[string]any{"type": "string", "format": "uri"}},
+			map[string]any{"name": headerRequestID, "in": "header", "schema": map[string]any{"type": "string"}},
+		},
+		"responses": map[string]any{
+			strconv.Itoa(http.StatusOK):           result,
+			strconv.Itoa(http.StatusCreated):      map[string]any{"description": "Operation started asynchronously", "content": jsonContent(schemaRef("OperationInfo"))},
+			strconv.Itoa(statusOperationFailed):   failureResponse,
+			strconv.Itoa(statusOperationRejected): map[string]any{"description": "Operation rejected", "content": jsonContent(schemaRef("Failure"))},
+			"default":                             errorResponse,
+		},
+	}
+	if body := g.requestBody(inputType); body != nil {
+		start["requestBody"] = body
+	}
+	paths[basePath] = map[string]any{"post": start}
+
+	paths[basePath+"/{operationId}"] = map[string]any{
+		"get": map[string]any{
+			"operationId": operationID("getInfo"),
+			"summary":     "Get information about " + name,
+			"tags":        []string{service},
+			"parameters":  []any{operationIDParam},
+			"responses": map[string]any{
+				strconv.Itoa(http.StatusOK): operationInfoResult,
+				"default":                   errorResponse,
+			},
+		},
+	}
+	paths[basePath+"/{operationId}/result"] = map[string]any{
+		"get": map[string]any{
+			"operationId": operationID("getResult"),
+			"summary":     "Get the result of " + name,
+			"tags":        []string{service},
+			"parameters": []any{
+				operationIDParam,
+				map[string]any{"name": queryWait, "in": "query", "description": "Duration to long poll for, e.g. 10s", "schema": map[string]any{"type": "string"}},
+			},
+			"responses": map[string]any{
+				strconv.Itoa(http.StatusOK):             result,
+				strconv.Itoa(http.StatusRequestTimeout): map[string]any{"description": "Wait period elapsed before the operation completed"},
+				strconv.Itoa(statusOperationRunning):    map[string]any{"description": "Operation still running"},
+				strconv.Itoa(statusOperationFailed):     failureResponse,
+				"default":                               errorResponse,
+			},
+		},
+	}
+	paths[basePath+"/{operationId}/cancel"] = map[string]any{
+		"post": map[string]any{
+			"operationId": operationID("cancel"),
+			"summary":     "Cancel " + name,
+			"tags":        []string{service},
+			"parameters":  []any{operationIDParam},
+			"responses": map[string]any{
+				strconv.Itoa(http.StatusAccepted): map[string]any{"description": "Cancelation requested"},
+				"default":                         errorResponse,
+			},
+		},
+	}
+}
+
+func (g *openAPIGenerator) requestBody(t reflect.Type) map[string]any {
+	if t == noValueType {
+		return nil
+	}
+	return map[string]any{"required": true, "content": g.content(t)}
+}
+
+func (g *openAPIGenerator) resultResponse(t reflect.Type) map[string]any {
+	response := map[string]any{"description": "Operation succeeded"}
+	if t != noValueType {
+		response["content"] = g.content(t)
+	}
+	return response
+}
+
+func (g *openAPIGenerator) content(t reflect.Type) map[string]any {
+	if t == byteSliceType {
+		return map[string]any{"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}}
+	}
+	return jsonContent(g.schema(t))
+}
+
+// schema returns the JSON schema for values of the given type as encoded by encoding/json.
+func (g *openAPIGenerator) schema(t reflect.Type) map[string]any {
+	if t == nil {
+		return map[string]any{"type": "object"}
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return map[string]any{"type": "object"}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return map[string]any{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Pointer:
+		schema := g.schema(t.Elem())
+		if _, isRef := schema["$ref"]; isRef {
+			// Siblings of $ref are ignored in OpenAPI 3.0.
+			return map[string]any{"allOf": []any{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			// encoding/json encodes byte slices as base64 strings.
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+		}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := invalidSchemaKeyRE.ReplaceAllString(t.String(), "_")
+		if _, ok := g.schemas[name]; !ok {
+			// Register a placeholder first to terminate recursion for self referencing types.
+			g.schemas[name] = map[string]any{}
+			g.schemas[name] = g.structSchema(t)
+		}
+		return schemaRef(name)
+	}
+	return map[string]any{"type": "object"}
+}
+
+func (g *openAPIGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	g.addStructProperties(t, properties)
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+func (g *openAPIGenerator) addStructProperties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				// Fields of embedded structs are promoted by encoding/json.
+				g.addStructProperties(ft, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+	}
+}
@@ -0,0 +1,96 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type openAPITestInput struct {
+	Name     string            `json:"name"`
+	Count    int               `json:"count,omitempty"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Created  time.Time         `json:"created"`
+	Next     *openAPITestInput `json:"next"`
+	Extra    any               `json:"extra"`
+	Ignored  string            `json:"-"`
+	internal string            //nolint:unused
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	svc := NewService("svc")
+	require.NoError(t, svc.Register(
+		NewSyncOperation("typed", func(ctx context.Context, input openAPITestInput, options StartOperationOptions) (int, error) {
+			return 0, nil
+		}),
+		bytesIOOperation,
+		noValueOperation,
+	))
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.Register(svc))
+
+	b, err := registry.OpenAPISpec()
+	require.NoError(t, err)
+	var spec struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(b, &spec))
+	require.Equal(t, "3.0.3", spec.OpenAPI)
+
+	for _, op := range []string{"typed", "bytes-io", "no-value"} {
+		require.Contains(t, spec.Paths["/svc/"+op], "post")
+		require.Contains(t, spec.Paths["/svc/"+op+"/{operationId}"], "get")
+		require.Contains(t, spec.Paths["/svc/"+op+"/{operationId}/result"], "get")
+		require.Contains(t, spec.Paths["/svc/"+op+"/{operationId}/cancel"], "post")
+	}
+
+	// JSON input is described by a component schema.
+	require.Equal(t, map[string]any{
+		"required": true,
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": map[string]any{"$ref": "#/components/schemas/nexus.openAPITestInput"},
+			},
+		},
+	}, spec.Paths["/svc/typed"]["post"]["requestBody"])
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":    map[string]any{"type": "string"},
+			"count":   map[string]any{"type": "integer", "format": "int64"},
+			"tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"labels":  map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"created": map[string]any{"type": "string", "format": "date-time"},
+			"next": map[string]any{
+				"allOf":    []any{map[string]any{"$ref": "#/components/schemas/nexus.openAPITestInput"}},
+				"nullable": true,
+			},
+			"extra": map[string]any{"type": "object"},
+		},
+	}, spec.Components.Schemas["nexus.openAPITestInput"])
+	result := spec.Paths["/svc/typed/{operationId}/result"]["get"]["responses"].(map[string]any)["200"]
+	require.Equal(t, map[string]any{
+		"description": "Operation succeeded",
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": map[string]any{"type": "integer", "format": "int64"}},
+		},
+	}, result)
+
+	// Byte slices are sent as binary content.
+	require.Equal(t, map[string]any{
+		"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+	}, spec.Paths["/svc/bytes-io"]["post"]["requestBody"].(map[string]any)["content"])
+
+	// NoValue has no body.
+	require.NotContains(t, spec.Paths["/svc/no-value"]["post"], "requestBody")
+	result = spec.Paths["/svc/no-value"]["post"]["responses"].(map[string]any)["200"]
+	require.NotContains(t, result, "content")
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
 // NoValue is a marker type for an operations that do not accept any input or return a value (nil).
@@ -96,7 +100,19 @@ type Operation[I, O any] interface {
 	//  1. asynchronous - returning from this method only ensures that cancelation is delivered, it may later be
 	//  ignored by the underlying operation implemention.
 	//  2. idempotent - implementors should ignore duplicate cancelations for the same operation.
-	Cancel(context.Context, string, CancelOperationOptions) error
+	//
+	// Implementors may return a nil result if there are no details to report back to the caller.
+	Cancel(context.Context, string, CancelOperationOptions) (*CancelOperationResult, error)
+	// RegisterCallback handles requests to register an additional completion callback for an asynchronous operation
+	// after it was started. See [Handler.RegisterOperationCallback] for the expected semantics.
+	RegisterCallback(context.Context, string, RegisterOperationCallbackOptions) error
+}
+
+// InputValidator may be implemented by an [Operation] to support dry run start requests, see
+// [StartOperationOptions.DryRun]. ValidateInput is called instead of Start for dry runs and must not have side effects.
+// Return a [HandlerError] of type [HandlerErrorTypeBadRequest] to reject invalid input.
+type InputValidator[I any] interface {
+	ValidateInput(context.Context, I, StartOperationOptions) error
 }
 
 type syncOperation[I, O any] struct {
@@ -129,26 +145,71 @@ func (h *syncOperation[I, O]) Start(ctx context.Context, input I, options StartO
 }
 
 // A Service is a container for a group of operations.
+//
+// Operations may be registered and unregistered at any time, including after a handler serving the service was created
+// with [ServiceRegistry.NewHandler], e.g. to add operations based on runtime configuration, except for operations that
+// implement [Initializer] or [Closer], see [Service.Register]. Requests for operations that are not registered fail
+// with a [HandlerErrorTypeNotFound] error.
 type Service struct {
 	Name string
 
-	operations map[string]RegisterableOperation
+	mu               sync.RWMutex
+	operations       map[string]RegisterableOperation
+	operationOptions map[string]OperationOptions
+	// Number of registries with open handlers serving the service, while positive operations that need to be
+	// initialized or closed may not be registered.
+	servers int
 }
 
 // NewService constructs a [Service].
 func NewService(name string) *Service {
 	return &Service{
-		Name:       name,
-		operations: make(map[string]RegisterableOperation),
+		Name:             name,
+		operations:       make(map[string]RegisterableOperation),
+		operationOptions: make(map[string]OperationOptions),
 	}
 }
 
+// OperationOptions are options for registering an operation with [Service.RegisterWithOptions].
+type OperationOptions struct {
+	// Max duration to allow waiting for a single get result request for this operation.
+	// Overrides [HandlerOptions.GetResultTimeout] when greater than zero.
+	GetResultTimeout time.Duration
+	// Media types of inputs accepted by this operation, e.g. "application/json". Start requests with a different
+	// content type are rejected with a [HandlerErrorTypeBadRequest] error. Empty inputs, which have no content type,
+	// are always accepted. Any content type is accepted if empty.
+	AcceptContentTypes []string
+	// Media types of results this operation may produce. Results of a different content type, as determined by the
+	// handler's [Serializer] or set on a [Content] or [Reader] result, are replaced with a [HandlerErrorTypeInternal]
+	// error. Empty results are always allowed. Any content type is allowed if empty.
+	ProduceContentTypes []string
+}
+
 // Register one or more operations.
 // Returns an error if duplicate operations were registered with the same name or when trying to register an operation
 // with no name.
 //
-// Can be called multiple times and is not thread safe.
+// Can be called multiple times and is safe to call concurrently with requests served by handlers created with
+// [ServiceRegistry.NewHandler]. Operations that implement [Initializer] or [Closer] can only be registered while no
+// handler serving the service is open, since handlers initialize and close them; registering them otherwise returns an
+// error.
 func (s *Service) Register(operations ...RegisterableOperation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.register(operations, nil)
+}
+
+// register registers operations with the given options, must be called with the lock held.
+func (s *Service) register(operations []RegisterableOperation, options *OperationOptions) error {
+	if s.servers > 0 {
+		for _, op := range operations {
+			_, initializer := op.(Initializer)
+			_, closer := op.(Closer)
+			if initializer || closer {
+				return fmt.Errorf("operation %q implements Initializer or Closer and cannot be registered while a handler serving service %q is open", op.Name(), s.Name)
+			}
+		}
+	}
 	var dups []string
 	for _, op := range operations {
 		if op.Name() == "" {
@@ -158,6 +219,9 @@ func (s *Service) Register(operations ...RegisterableOperation) error {
 			dups = append(dups, op.Name())
 		} else {
 			s.operations[op.Name()] = op
+			if options != nil {
+				s.operationOptions[op.Name()] = *options
+			}
 		}
 	}
 	if len(dups) > 0 {
@@ -166,14 +230,103 @@ func (s *Service) Register(operations ...RegisterableOperation) error {
 	return nil
 }
 
+// RegisterWithOptions registers a single operation with the given options.
+// Returns an error if an operation was already registered with the same name or when trying to register an operation
+// with no name.
+//
+// Can be called multiple times and is safe to call concurrently, see [Service.Register].
+func (s *Service) RegisterWithOptions(operation RegisterableOperation, options OperationOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.register([]RegisterableOperation{operation}, &options)
+}
+
+// Unregister removes the operation with the given name, returning it or nil if not found. Requests for the operation
+// that are already in flight are not affected.
+//
+// Safe to call concurrently with requests served by handlers created with [ServiceRegistry.NewHandler]. Operations that
+// implement [Closer] remain owned by the handlers that initialized them and are closed when the last of those handlers
+// is closed, since in flight requests may still use them.
+func (s *Service) Unregister(name string) RegisterableOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	op := s.operations[name]
+	delete(s.operations, name)
+	delete(s.operationOptions, name)
+	return op
+}
+
 // Operation returns an operation by name or nil if not found.
 func (s *Service) Operation(name string) RegisterableOperation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.operations[name]
 }
 
+// options returns the options the named operation was registered with, and whether any were set.
+func (s *Service) options(name string) (OperationOptions, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	options, ok := s.operationOptions[name]
+	return options, ok
+}
+
+// snapshot returns a copy of the registered operations keyed by name.
+func (s *Service) snapshot() map[string]RegisterableOperation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.operations)
+}
+
+// serve marks the service as served by a handler and returns a copy of the registered operations keyed by name.
+func (s *Service) serve() map[string]RegisterableOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.servers++
+	return maps.Clone(s.operations)
+}
+
+// release undoes a call to serve once the handlers serving the service are closed or failed to be created.
+func (s *Service) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.servers--
+}
+
+// resolve returns the name of the registered operation that matches the given name case-insensitively, preferring an
+// exact match. The name is returned as is if there is no single matching operation.
+func (s *Service) resolve(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.operations[name]; ok {
+		return name
+	}
+	resolved := name
+	matches := 0
+	for registered := range s.operations {
+		if strings.EqualFold(registered, name) {
+			resolved = registered
+			matches++
+		}
+	}
+	if matches != 1 {
+		return name
+	}
+	return resolved
+}
+
 // A ServiceRegistry registers services and constructs a [Handler] that dispatches operations requests to those services.
 type ServiceRegistry struct {
-	services map[string]*Service
+	services   map[string]*Service
+	middleware []MiddlewareFunc
+
+	// Guards the lifecycle of the operations shared by handlers created with NewHandler.
+	lifecycleMu sync.Mutex
+	// Number of handlers created with NewHandler that were not closed yet.
+	openHandlers int
+	// Services marked as served and initialized operations to close once the last open handler is closed.
+	served  []*Service
+	closers []RegisterableOperation
 }
 
 func NewServiceRegistry() *ServiceRegistry {
@@ -203,41 +356,403 @@ func (r *ServiceRegistry) Register(services ...*Service) error {
 	return nil
 }
 
-// NewHandler creates a [Handler] that dispatches requests to registered operations based on their name.
+// Use registers one or more middleware to be applied to all operation method invocations across all registered
+// services. Middleware is applied in registration order, the first registered middleware is the outermost one.
+//
+// Can be called multiple times and is not thread safe. Must be called before [ServiceRegistry.NewHandler].
+func (r *ServiceRegistry) Use(middleware ...MiddlewareFunc) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// UseFor registers one or more middleware to be applied only to method invocations of the operations with the given
+// names, across all registered services.
+//
+// Middleware registered with [ServiceRegistry.Use] and UseFor form a single chain ordered by registration, the first
+// registered middleware is the outermost one. Scoped middleware is skipped for invocations of other operations.
+//
+// Can be called multiple times and is not thread safe. Must be called before [ServiceRegistry.NewHandler].
+func (r *ServiceRegistry) UseFor(operations []string, middleware ...MiddlewareFunc) {
+	operations = slices.Clone(operations)
+	for _, mw := range middleware {
+		mw := mw
+		r.middleware = append(r.middleware, func(ctx context.Context, next Handler) (Handler, error) {
+			if info, _ := ExtractHandlerInfo(ctx); !slices.Contains(operations, info.Operation) {
+				return next, nil
+			}
+			return mw(ctx, next)
+		})
+	}
+}
+
+// Initializer can be implemented by operations that need to set up resources before serving requests, see
+// [ServiceRegistry.NewHandler].
+type Initializer interface {
+	Initialize(context.Context) error
+}
+
+// Closer can be implemented by operations that need to release resources once they no longer serve requests, see
+// [ServiceRegistry.NewHandler].
+type Closer interface {
+	Close() error
+}
+
+// NewHandler creates a [Handler] that dispatches requests to registered operations based on their name. It is
+// equivalent to calling [ServiceRegistry.NewHandlerWithContext] with [context.Background].
 func (r *ServiceRegistry) NewHandler() (Handler, error) {
+	return r.NewHandlerWithContext(context.Background())
+}
+
+// NewHandlerWithContext creates a [Handler] that dispatches requests to registered operations based on their name.
+//
+// Operations that implement [Initializer] are initialized with the given context when the first handler of the
+// registry is created, handlers created while it is open share the initialized operations. If any of them fail to
+// initialize, the already initialized operations that implement [Closer] are closed and an error joining all
+// initialization errors is returned. Operations that implement [Closer] are closed once all handlers of the registry
+// are closed, with all close errors joined in the error returned by the last handler's Close. Operations that
+// implement either interface can't be registered while a handler serving their service is open.
+func (r *ServiceRegistry) NewHandlerWithContext(ctx context.Context) (Handler, error) {
 	if len(r.services) == 0 {
 		return nil, errors.New("must register at least one service")
 	}
 	for _, service := range r.services {
-		if len(service.operations) == 0 {
+		if len(service.snapshot()) == 0 {
 			return nil, fmt.Errorf("service %q has no operations registered", service.Name)
 		}
 	}
 
-	return &registryHandler{services: r.services}, nil
+	r.lifecycleMu.Lock()
+	defer r.lifecycleMu.Unlock()
+	if r.openHandlers == 0 {
+		if err := r.initialize(ctx); err != nil {
+			return nil, err
+		}
+	}
+	r.openHandlers++
+	return &registryHandler{registry: r, services: r.services, middleware: slices.Clone(r.middleware)}, nil
 }
 
+// initialize initializes the registered operations, must be called with the lifecycle lock held.
+func (r *ServiceRegistry) initialize(ctx context.Context) error {
+	// Mark services as served as their operations are collected, for operations that need to be initialized to only be
+	// registered before.
+	operations := make(map[string]map[string]RegisterableOperation, len(r.services))
+	for name, service := range r.services {
+		operations[name] = service.serve()
+		r.served = append(r.served, service)
+	}
+
+	var errs []error
+	for _, serviceOperations := range operations {
+		for _, op := range serviceOperations {
+			if initializer, ok := op.(Initializer); ok {
+				if err := initializer.Initialize(ctx); err != nil {
+					errs = append(errs, fmt.Errorf("failed to initialize operation %q: %w", op.Name(), err))
+					continue
+				}
+			}
+			if _, ok := op.(Closer); ok {
+				r.closers = append(r.closers, op)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		if err := r.close(); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// close closes the initialized operations and releases the served services, must be called with the lifecycle lock
+// held.
+func (r *ServiceRegistry) close() error {
+	var errs []error
+	for _, op := range r.closers {
+		if err := op.(Closer).Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close operation %q: %w", op.Name(), err))
+		}
+	}
+	for _, service := range r.served {
+		service.release()
+	}
+	r.served = nil
+	r.closers = nil
+	return errors.Join(errs...)
+}
+
+// HandlerInfo contains the general information for an operation invocation, across the different handler methods.
+type HandlerInfo struct {
+	// Service is the name of the service that contains the operation.
+	Service string
+	// Operation is the name of the operation.
+	Operation string
+	// Header contains the request header fields received by the server.
+	Header Header
+}
+
+type handlerInfoKeyType struct{}
+
+var handlerInfoKey = handlerInfoKeyType{}
+
+// ExtractHandlerInfo extracts the [HandlerInfo] from a given context.
+// The info is available to [MiddlewareFunc] and [Operation] implementations invoked via a handler constructed by
+// [ServiceRegistry.NewHandler]. Returns false if the info is not present in the given context.
+func ExtractHandlerInfo(ctx context.Context) (HandlerInfo, bool) {
+	info, ok := ctx.Value(handlerInfoKey).(HandlerInfo)
+	return info, ok
+}
+
+// HandlerValueKey is a typed key for attaching values to a handler request context with [WithHandlerValue] and
+// retrieving them with [HandlerValue]. Keys are compared by identity, create them once with [NewHandlerValueKey] and
+// share them between the middleware and operations that communicate through them.
+type HandlerValueKey[T any] struct {
+	name string
+}
+
+// NewHandlerValueKey creates a new [HandlerValueKey] for values of type T. The name is used for debugging purposes
+// only.
+func NewHandlerValueKey[T any](name string) *HandlerValueKey[T] {
+	return &HandlerValueKey[T]{name: name}
+}
+
+// String returns the key's name.
+func (k *HandlerValueKey[T]) String() string {
+	return k.name
+}
+
+// WithHandlerValue returns a copy of ctx that carries the given typed value. Unlike [Header], values attached this way
+// are never sent over the wire, which makes them suitable for passing non-serializable data, such as a parsed
+// authentication principal, from a [MiddlewareFunc] to later middleware and operations.
+//
+// Values are scoped to a single handler method invocation (i.e. a single request): a middleware handler should attach
+// values to the context it passes to the next handler. As with any context value, the context itself is immutable and
+// safe for concurrent use, but the attached values must be safe for concurrent access if they are shared across
+// goroutines.
+func WithHandlerValue[T any](ctx context.Context, key *HandlerValueKey[T], value T) context.Context {
+	return context.WithValue(ctx, key, value)
+}
+
+// HandlerValue returns the value attached to ctx for the given key with [WithHandlerValue], and whether it was found.
+func HandlerValue[T any](ctx context.Context, key *HandlerValueKey[T]) (T, bool) {
+	value, ok := ctx.Value(key).(T)
+	return value, ok
+}
+
+// MiddlewareFunc is a function which receives a [Handler] and returns another [Handler], wrapping it with additional
+// behavior. The returned handler will typically embed the next handler to delegate the methods it does not intercept.
+//
+// The context carries the [HandlerInfo] for the current invocation, accessible via [ExtractHandlerInfo].
+//
+// StartOperation is also invoked for dry run requests that only validate the request, see
+// [StartOperationOptions.DryRun]. Middleware that has side effects when starting operations, e.g. reserving quota or
+// recording started operations, must check the DryRun option and skip them for dry runs.
+//
+// If a middleware wants to stop the chain before any handler method is called, it can return an error which will be
+// returned to the caller. Return a [HandlerError] to control the error type.
+type MiddlewareFunc func(ctx context.Context, next Handler) (Handler, error)
+
 type registryHandler struct {
 	UnimplementedHandler
 
-	services map[string]*Service
+	registry   *ServiceRegistry
+	services   map[string]*Service
+	middleware []MiddlewareFunc
+	// Set once the handler was closed, guarded by the registry's lifecycle lock.
+	closed bool
+}
+
+// Close implements Handler. Closes the registry's initialized operations if this is the last open handler of the
+// registry. Subsequent calls are no-ops.
+func (r *registryHandler) Close(ctx context.Context) error {
+	r.registry.lifecycleMu.Lock()
+	defer r.registry.lifecycleMu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.registry.openHandlers--
+	if r.registry.openHandlers > 0 {
+		return nil
+	}
+	return r.registry.close()
+}
+
+// operationHandler looks up the given operation and returns a [Handler] for it, wrapped with the registry's
+// middleware, and a context that carries the invocation's [HandlerInfo].
+func (r *registryHandler) operationHandler(ctx context.Context, service, operation string, header Header) (context.Context, Handler, error) {
+	s, ok := r.services[service]
+	if !ok {
+		return nil, nil, HandlerErrorf(HandlerErrorTypeNotFound, "service %q not found", service)
+	}
+	op := s.Operation(operation)
+	if op == nil {
+		return nil, nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation %q not found", operation)
+	}
+
+	ctx = context.WithValue(ctx, handlerInfoKey, HandlerInfo{Service: service, Operation: operation, Header: header})
+	var h Handler = &reflectionHandler{op: op}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		var err error
+		if h, err = r.middleware[i](ctx, h); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ctx, h, nil
 }
 
 // CancelOperation implements Handler.
-func (r *registryHandler) CancelOperation(ctx context.Context, service, operation string, operationID string, options CancelOperationOptions) error {
+func (r *registryHandler) CancelOperation(ctx context.Context, service, operation string, operationID string, options CancelOperationOptions) (*CancelOperationResult, error) {
+	ctx, h, err := r.operationHandler(ctx, service, operation, options.Header)
+	if err != nil {
+		return nil, err
+	}
+	return h.CancelOperation(ctx, service, operation, operationID, options)
+}
+
+// RegisterOperationCallback implements Handler.
+func (r *registryHandler) RegisterOperationCallback(ctx context.Context, service, operation string, operationID string, options RegisterOperationCallbackOptions) error {
+	ctx, h, err := r.operationHandler(ctx, service, operation, options.Header)
+	if err != nil {
+		return err
+	}
+	return h.RegisterOperationCallback(ctx, service, operation, operationID, options)
+}
+
+// GetOperationInfo implements Handler.
+func (r *registryHandler) GetOperationInfo(ctx context.Context, service, operation string, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	ctx, h, err := r.operationHandler(ctx, service, operation, options.Header)
+	if err != nil {
+		return nil, err
+	}
+	return h.GetOperationInfo(ctx, service, operation, operationID, options)
+}
+
+// GetOperationResult implements Handler.
+func (r *registryHandler) GetOperationResult(ctx context.Context, service, operation string, operationID string, options GetOperationResultOptions) (any, error) {
+	ctx, h, err := r.operationHandler(ctx, service, operation, options.Header)
+	if err != nil {
+		return nil, err
+	}
+	return h.GetOperationResult(ctx, service, operation, operationID, options)
+}
+
+// StartOperation implements Handler.
+func (r *registryHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	ctx, h, err := r.operationHandler(ctx, service, operation, options.Header)
+	if err != nil {
+		return nil, err
+	}
+	return h.StartOperation(ctx, service, operation, input, options)
+}
+
+func (r *registryHandler) describeServices() []ServiceDescription {
+	services := make([]ServiceDescription, 0, len(r.services))
+	for name, s := range r.services {
+		operations := s.snapshot()
+		service := ServiceDescription{Name: name, Operations: make([]OperationDescription, 0, len(operations))}
+		for _, op := range operations {
+			operation := OperationDescription{Name: op.Name()}
+			if typed, ok := op.(interface {
+				InputType() reflect.Type
+				OutputType() reflect.Type
+			}); ok {
+				operation.InputType = typed.InputType().String()
+				operation.OutputType = typed.OutputType().String()
+			}
+			service.Operations = append(service.Operations, operation)
+		}
+		slices.SortFunc(service.Operations, func(a, b OperationDescription) int { return strings.Compare(a.Name, b.Name) })
+		services = append(services, service)
+	}
+	slices.SortFunc(services, func(a, b ServiceDescription) int { return strings.Compare(a.Name, b.Name) })
+	return services
+}
+
+// operationOptionsProvider is implemented by handlers that support per-operation options.
+type operationOptionsProvider interface {
+	operationOptions(service, operation string) (OperationOptions, bool)
+}
+
+func (r *registryHandler) operationOptions(service, operation string) (OperationOptions, bool) {
 	s, ok := r.services[service]
 	if !ok {
-		return HandlerErrorf(HandlerErrorTypeNotFound, "service %q not found", service)
+		return OperationOptions{}, false
 	}
-	h, ok := s.operations[operation]
+	return s.options(operation)
+}
+
+// operationNameResolver is implemented by handlers that support case-insensitive operation names.
+type operationNameResolver interface {
+	resolveOperationName(service, operation string) string
+}
+
+// resolveOperationName returns the name of the registered operation that matches the given name case-insensitively,
+// preferring an exact match. The name is returned as is if there is no single matching operation.
+func (r *registryHandler) resolveOperationName(service, operation string) string {
+	s, ok := r.services[service]
 	if !ok {
-		return HandlerErrorf(HandlerErrorTypeNotFound, "operation %q not found", operation)
+		return operation
 	}
+	return s.resolve(operation)
+}
+
+// SupportsDryRun implements DryRunHandler. Unknown operations are reported as supported for StartOperation to respond
+// with a not found error.
+func (r *registryHandler) SupportsDryRun(service, operation string) bool {
+	s, ok := r.services[service]
+	if !ok {
+		return true
+	}
+	op := s.Operation(operation)
+	if op == nil {
+		return true
+	}
+	_, ok = validateInputMethod(op)
+	return ok
+}
+
+// validateInputMethod returns the ValidateInput method of an operation that implements [InputValidator] for its input
+// type.
+func validateInputMethod(op RegisterableOperation) (reflect.Method, bool) {
+	t := reflect.TypeOf(op)
+	start, _ := t.MethodByName("Start")
+	m, ok := t.MethodByName("ValidateInput")
+	if !ok || m.Type != reflect.FuncOf(
+		[]reflect.Type{t, reflect.TypeOf((*context.Context)(nil)).Elem(), start.Type.In(2), reflect.TypeOf(StartOperationOptions{})},
+		[]reflect.Type{reflect.TypeOf((*error)(nil)).Elem()},
+		false,
+	) {
+		return reflect.Method{}, false
+	}
+	return m, true
+}
+
+// reflectionHandler is a [Handler] that invokes the generic methods of a single registered operation.
+type reflectionHandler struct {
+	UnimplementedHandler
+
+	op RegisterableOperation
+}
 
+// CancelOperation implements Handler.
+func (r *reflectionHandler) CancelOperation(ctx context.Context, service, operation string, operationID string, options CancelOperationOptions) (*CancelOperationResult, error) {
+	h := r.op
 	// NOTE: We could avoid reflection here if we put the Cancel method on RegisterableOperation but it doesn't seem
 	// worth it since we need reflection for the generic methods.
 	m, _ := reflect.TypeOf(h).MethodByName("Cancel")
 	values := m.Func.Call([]reflect.Value{reflect.ValueOf(h), reflect.ValueOf(ctx), reflect.ValueOf(operationID), reflect.ValueOf(options)})
+	if !values[1].IsNil() {
+		return nil, values[1].Interface().(error)
+	}
+	return values[0].Interface().(*CancelOperationResult), nil
+}
+
+// RegisterOperationCallback implements Handler.
+func (r *reflectionHandler) RegisterOperationCallback(ctx context.Context, service, operation string, operationID string, options RegisterOperationCallbackOptions) error {
+	h := r.op
+	m, _ := reflect.TypeOf(h).MethodByName("RegisterCallback")
+	values := m.Func.Call([]reflect.Value{reflect.ValueOf(h), reflect.ValueOf(ctx), reflect.ValueOf(operationID), reflect.ValueOf(options)})
 	if values[0].IsNil() {
 		return nil
 	}
@@ -245,16 +760,8 @@ func (r *registryHandler) CancelOperation(ctx context.Context, service, operatio
 }
 
 // GetOperationInfo implements Handler.
-func (r *registryHandler) GetOperationInfo(ctx context.Context, service, operation string, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
-	s, ok := r.services[service]
-	if !ok {
-		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "service %q not found", service)
-	}
-	h, ok := s.operations[operation]
-	if !ok {
-		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation %q not found", operation)
-	}
-
+func (r *reflectionHandler) GetOperationInfo(ctx context.Context, service, operation string, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	h := r.op
 	// NOTE: We could avoid reflection here if we put the Cancel method on RegisterableOperation but it doesn't seem
 	// worth it since we need reflection for the generic methods.
 	m, _ := reflect.TypeOf(h).MethodByName("GetInfo")
@@ -267,16 +774,8 @@ func (r *registryHandler) GetOperationInfo(ctx context.Context, service, operati
 }
 
 // GetOperationResult implements Handler.
-func (r *registryHandler) GetOperationResult(ctx context.Context, service, operation string, operationID string, options GetOperationResultOptions) (any, error) {
-	s, ok := r.services[service]
-	if !ok {
-		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "service %q not found", service)
-	}
-	h, ok := s.operations[operation]
-	if !ok {
-		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation %q not found", operation)
-	}
-
+func (r *reflectionHandler) GetOperationResult(ctx context.Context, service, operation string, operationID string, options GetOperationResultOptions) (any, error) {
+	h := r.op
 	m, _ := reflect.TypeOf(h).MethodByName("GetResult")
 	values := m.Func.Call([]reflect.Value{reflect.ValueOf(h), reflect.ValueOf(ctx), reflect.ValueOf(operationID), reflect.ValueOf(options)})
 	if !values[1].IsNil() {
@@ -287,25 +786,33 @@ func (r *registryHandler) GetOperationResult(ctx context.Context, service, opera
 }
 
 // StartOperation implements Handler.
-func (r *registryHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
-	s, ok := r.services[service]
-	if !ok {
-		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "service %q not found", service)
-	}
-	h, ok := s.operations[operation]
-	if !ok {
-		return nil, HandlerErrorf(HandlerErrorTypeNotFound, "operation %q not found", operation)
-	}
-
+func (r *reflectionHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	h := r.op
 	m, _ := reflect.TypeOf(h).MethodByName("Start")
 	inputType := m.Type.In(2)
 	iptr := reflect.New(inputType).Interface()
 	if err := input.Consume(iptr); err != nil {
+		var deserializeError *DeserializeError
+		if errors.As(err, &deserializeError) && deserializeError.Unsupported {
+			return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "unsupported input content type %q", deserializeError.ContentType)
+		}
 		// TODO: log the error? Do we need to accept a logger for this single line?
 		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid input")
 	}
 	i := reflect.ValueOf(iptr).Elem()
 
+	if options.DryRun {
+		m, ok := validateInputMethod(h)
+		if !ok {
+			return nil, HandlerErrorf(HandlerErrorTypeNotImplemented, "operation %q does not support dry run", operation)
+		}
+		values := m.Func.Call([]reflect.Value{reflect.ValueOf(h), reflect.ValueOf(ctx), i, reflect.ValueOf(options)})
+		if !values[0].IsNil() {
+			return nil, values[0].Interface().(error)
+		}
+		return &HandlerStartOperationResultDryRun{}, nil
+	}
+
 	values := m.Func.Call([]reflect.Value{reflect.ValueOf(h), reflect.ValueOf(ctx), i, reflect.ValueOf(options)})
 	if !values[1].IsNil() {
 		return nil, values[1].Interface().(error)
@@ -315,6 +822,8 @@ func (r *registryHandler) StartOperation(ctx context.Context, service, operation
 }
 
 var _ Handler = &registryHandler{}
+var _ DryRunHandler = &registryHandler{}
+var _ Handler = &reflectionHandler{}
 
 // ExecuteOperation is the type safe version of [HTTPClient.ExecuteOperation].
 // It accepts input of type I and returns output of type O, removing the need to consume the [LazyValue] returned by the
@@ -339,6 +848,9 @@ func StartOperation[I, O any](ctx context.Context, client *HTTPClient, operation
 	if err != nil {
 		return nil, err
 	}
+	if result.DryRun {
+		return &ClientStartOperationResult[O]{DryRun: true, Links: result.Links}, nil
+	}
 	if result.Successful != nil {
 		var o O
 		if err := result.Successful.Consume(&o); err != nil {
@@ -364,3 +876,33 @@ func NewHandle[I, O any](client *HTTPClient, operation OperationReference[I, O],
 	}
 	return &OperationHandle[O]{client: client, Operation: operation.Name(), ID: operationID}, nil
 }
+
+// GetOperationResult is the type safe version of [OperationHandle.GetResult] for operations identified by a previously
+// obtained operation ID. It builds a handle and decodes the result into O.
+//
+// If the operation completed unsuccessfully, returns the zero value of O and an [UnsuccessfulOperationError].
+//
+//	ref := NewOperationReference[MyInput, MyOutput]("my-operation")
+//	out, err := GetOperationResult(ctx, client, ref, operationID, options) // returns MyOutput, error
+func GetOperationResult[I, O any](ctx context.Context, client *HTTPClient, operation OperationReference[I, O], operationID string, options GetOperationResultOptions) (O, error) {
+	result, err := GetOperationResultWithDetails(ctx, client, operation, operationID, options)
+	if err != nil {
+		var o O
+		return o, err
+	}
+	return result.Result, nil
+}
+
+// GetOperationResultWithDetails is like [GetOperationResult] but also returns the links attached by the handler to the
+// result response.
+func GetOperationResultWithDetails[I, O any](ctx context.Context, client *HTTPClient, operation OperationReference[I, O], operationID string, options GetOperationResultOptions) (*ClientGetOperationResult[O], error) {
+	handle, err := NewHandle(client, operation, operationID)
+	if err != nil {
+		return nil, err
+	}
+	result, links, metadata, err := handle.getResultWithOperationTimeout(ctx, options, false)
+	if err != nil {
+		return nil, client.annotateError(operation.Name(), err)
+	}
+	return &ClientGetOperationResult[O]{Result: result, Links: links, Metadata: metadata}, nil
+}
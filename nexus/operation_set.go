@@ -0,0 +1,86 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// OperationDefinition describes an operation's name and types. Every [OperationReference] and [Operation] is an
+// OperationDefinition.
+type OperationDefinition interface {
+	Name() string
+	// InputType the generic input type I for this operation.
+	InputType() reflect.Type
+	// OutputType the generic out type O for this operation.
+	OutputType() reflect.Type
+}
+
+// ClientOperationSet holds typed operation definitions to invoke with an [HTTPClient] by name, validating the types
+// used for each invocation against the registered definition. Execute operations in a set with [ExecuteByName].
+type ClientOperationSet struct {
+	client     *HTTPClient
+	operations map[string]OperationDefinition
+}
+
+// NewClientOperationSet creates a [ClientOperationSet] that invokes the given operations with client.
+// Returns an error if duplicate operations were registered with the same name or when trying to register an operation
+// with no name.
+//
+//	set, err := NewClientOperationSet(client, NewOperationReference[MyInput, MyOutput]("my-operation"))
+//	out, err := ExecuteByName[MyOutput](ctx, set, "my-operation", MyInput{}, options)
+func NewClientOperationSet(client *HTTPClient, operations ...OperationDefinition) (*ClientOperationSet, error) {
+	set := &ClientOperationSet{client: client, operations: make(map[string]OperationDefinition, len(operations))}
+	var dups []string
+	for _, op := range operations {
+		if op.Name() == "" {
+			return nil, fmt.Errorf("tried to register an operation with no name")
+		}
+		if _, found := set.operations[op.Name()]; found {
+			dups = append(dups, op.Name())
+		} else {
+			set.operations[op.Name()] = op
+		}
+	}
+	if len(dups) > 0 {
+		return nil, fmt.Errorf("duplicate operations: %s", strings.Join(dups, ", "))
+	}
+	return set, nil
+}
+
+// Operation returns an operation definition by name or nil if not found.
+func (s *ClientOperationSet) Operation(name string) OperationDefinition {
+	return s.operations[name]
+}
+
+// ExecuteByName executes the named operation of the set, see [HTTPClient.ExecuteOperation], and returns its result
+// decoded as O.
+//
+// Before sending any request, returns an error if the operation is not in the set, if the input is not assignable to
+// the operation's input type, or if the operation's output type is not assignable to O.
+func ExecuteByName[O any](ctx context.Context, set *ClientOperationSet, name string, input any, options ExecuteOperationOptions) (O, error) {
+	var o O
+	op, ok := set.operations[name]
+	if !ok {
+		return o, fmt.Errorf("operation %q not found in set", name)
+	}
+	inputType := op.InputType()
+	if input == nil {
+		switch inputType.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+		default:
+			return o, fmt.Errorf("invalid input for operation %q: expected %s, got nil", name, inputType)
+		}
+	} else if actual := reflect.TypeOf(input); !actual.AssignableTo(inputType) {
+		return o, fmt.Errorf("invalid input for operation %q: expected %s, got %s", name, inputType, actual)
+	}
+	if outputType := reflect.TypeOf((*O)(nil)).Elem(); !op.OutputType().AssignableTo(outputType) {
+		return o, fmt.Errorf("invalid output for operation %q: expected %s, got %s", name, op.OutputType(), outputType)
+	}
+	value, err := set.client.ExecuteOperation(ctx, name, input, options)
+	if err != nil {
+		return o, err
+	}
+	return o, value.Consume(&o)
+}
@@ -0,0 +1,56 @@
+package nexus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientOperationSet(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(numberValidatorOperation, bytesIOOperation, noValueOperation))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err = NewClientOperationSet(client, numberValidatorOperation, NewOperationReference[int, int](numberValidatorOperation.Name()))
+	require.ErrorContains(t, err, "duplicate operations: "+numberValidatorOperation.Name())
+	_, err = NewClientOperationSet(client, NewOperationReference[int, int](""))
+	require.ErrorContains(t, err, "tried to register an operation with no name")
+
+	set, err := NewClientOperationSet(client,
+		NewOperationReference[int, int](numberValidatorOperation.Name()),
+		bytesIOOperation,
+		noValueOperation,
+	)
+	require.NoError(t, err)
+	require.Equal(t, bytesIOOperation.Name(), set.Operation(bytesIOOperation.Name()).Name())
+	require.Nil(t, set.Operation("unknown"))
+
+	number, err := ExecuteByName[int](ctx, set, numberValidatorOperation.Name(), 3, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, number)
+
+	b, err := ExecuteByName[[]byte](ctx, set, bytesIOOperation.Name(), []byte("hello"), ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello, world"), b)
+
+	_, err = ExecuteByName[NoValue](ctx, set, noValueOperation.Name(), nil, ExecuteOperationOptions{})
+	require.NoError(t, err)
+
+	_, err = ExecuteByName[int](ctx, set, "unknown", 3, ExecuteOperationOptions{})
+	require.ErrorContains(t, err, `operation "unknown" not found in set`)
+
+	_, err = ExecuteByName[int](ctx, set, numberValidatorOperation.Name(), "3", ExecuteOperationOptions{})
+	require.ErrorContains(t, err, `invalid input for operation "number-validator": expected int, got string`)
+
+	_, err = ExecuteByName[int](ctx, set, numberValidatorOperation.Name(), nil, ExecuteOperationOptions{})
+	require.ErrorContains(t, err, `invalid input for operation "number-validator": expected int, got nil`)
+
+	_, err = ExecuteByName[string](ctx, set, numberValidatorOperation.Name(), 3, ExecuteOperationOptions{})
+	require.ErrorContains(t, err, `invalid output for operation "number-validator": expected int, got string`)
+}
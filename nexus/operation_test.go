@@ -2,10 +2,16 @@ package nexus
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -44,11 +50,11 @@ func (h *asyncNumberValidatorOperation) GetResult(ctx context.Context, id string
 	return strconv.Atoi(id)
 }
 
-func (h *asyncNumberValidatorOperation) Cancel(ctx context.Context, id string, options CancelOperationOptions) error {
+func (h *asyncNumberValidatorOperation) Cancel(ctx context.Context, id string, options CancelOperationOptions) (*CancelOperationResult, error) {
 	if options.Header.Get("fail") != "" {
-		return fmt.Errorf("intentionally failed")
+		return nil, fmt.Errorf("intentionally failed")
 	}
-	return nil
+	return nil, nil
 }
 
 func (h *asyncNumberValidatorOperation) GetInfo(ctx context.Context, id string, options GetOperationInfoOptions) (*OperationInfo, error) {
@@ -118,6 +124,34 @@ func TestExecuteOperation(t *testing.T) {
 	require.Nil(t, nResult)
 }
 
+func TestDynamicRegistration(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(noValueOperation))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	var handlerError *HandlerError
+	_, err = ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeNotFound, handlerError.Type)
+
+	require.NoError(t, svc.Register(numberValidatorOperation))
+	result, err := ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+
+	require.Equal(t, numberValidatorOperation, svc.Unregister(numberValidatorOperation.Name()))
+	require.Nil(t, svc.Unregister(numberValidatorOperation.Name()))
+	_, err = ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeNotFound, handlerError.Type)
+}
+
 func TestStartOperation(t *testing.T) {
 	registry := NewServiceRegistry()
 	svc := NewService(testService)
@@ -165,9 +199,11 @@ func TestCancelOperation(t *testing.T) {
 
 	result, err := StartOperation(ctx, client, asyncNumberValidatorOperationInstance, 3, StartOperationOptions{})
 	require.NoError(t, err)
-	require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{}))
+	_, err = result.Pending.Cancel(ctx, CancelOperationOptions{})
+	require.NoError(t, err)
 	var handlerError *HandlerError
-	require.ErrorAs(t, result.Pending.Cancel(ctx, CancelOperationOptions{Header: Header{"fail": "1"}}), &handlerError)
+	_, err = result.Pending.Cancel(ctx, CancelOperationOptions{Header: Header{"fail": "1"}})
+	require.ErrorAs(t, err, &handlerError)
 	require.Equal(t, HandlerErrorTypeInternal, handlerError.Type)
 	require.Equal(t, "internal server error", handlerError.Cause.Error())
 }
@@ -214,8 +250,8 @@ func (h *authRejectionHandler) GetResult(ctx context.Context, id string, options
 	return nil, HandlerErrorf(HandlerErrorTypeUnauthorized, "unauthorized in test")
 }
 
-func (h *authRejectionHandler) Cancel(ctx context.Context, id string, options CancelOperationOptions) error {
-	return HandlerErrorf(HandlerErrorTypeUnauthorized, "unauthorized in test")
+func (h *authRejectionHandler) Cancel(ctx context.Context, id string, options CancelOperationOptions) (*CancelOperationResult, error) {
+	return nil, HandlerErrorf(HandlerErrorTypeUnauthorized, "unauthorized in test")
 }
 
 func (h *authRejectionHandler) GetInfo(ctx context.Context, id string, options GetOperationInfoOptions) (*OperationInfo, error) {
@@ -249,7 +285,7 @@ func TestHandlerError(t *testing.T) {
 	require.Equal(t, HandlerErrorTypeUnauthorized, handlerError.Type)
 	require.Equal(t, "unauthorized in test", handlerError.Cause.Error())
 
-	err = handle.Cancel(ctx, CancelOperationOptions{})
+	_, err = handle.Cancel(ctx, CancelOperationOptions{})
 	require.ErrorAs(t, err, &handlerError)
 	require.Equal(t, HandlerErrorTypeUnauthorized, handlerError.Type)
 	require.Equal(t, "unauthorized in test", handlerError.Cause.Error())
@@ -267,3 +303,381 @@ func TestInputOutputType(t *testing.T) {
 	require.True(t, reflect.TypeOf(3).AssignableTo(numberValidatorOperation.OutputType()))
 	require.False(t, reflect.TypeOf("s").AssignableTo(numberValidatorOperation.OutputType()))
 }
+
+type quotaExhaustedHandler struct {
+	UnimplementedHandler
+	quota *Quota
+}
+
+func (h *quotaExhaustedHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return nil, &HandlerError{Type: HandlerErrorTypeResourceExhausted, Cause: errors.New("quota exhausted"), Quota: h.quota}
+}
+
+func TestHandlerError_Quota(t *testing.T) {
+	resetAt := time.Now().Add(time.Minute).Truncate(time.Second).UTC()
+	cases := []struct {
+		name  string
+		quota *Quota
+	}{
+		{name: "unset", quota: nil},
+		{name: "remaining only", quota: &Quota{Remaining: 0}},
+		{name: "remaining and reset", quota: &Quota{Remaining: 3, ResetAt: resetAt}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			ctx, client, teardown := setup(t, &quotaExhaustedHandler{quota: c.quota})
+			defer teardown()
+
+			_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+			var handlerError *HandlerError
+			require.ErrorAs(t, err, &handlerError)
+			require.Equal(t, HandlerErrorTypeResourceExhausted, handlerError.Type)
+			require.Equal(t, "quota exhausted", handlerError.Cause.Error())
+			require.Equal(t, c.quota, handlerError.Quota)
+		})
+	}
+}
+
+func TestIncludeOperationInErrors(t *testing.T) {
+	ctx, client, teardown := setup(t, &quotaExhaustedHandler{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var callError *OperationCallError
+	require.False(t, errors.As(err, &callError))
+
+	client.options.IncludeOperationInErrors = true
+
+	_, err = client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.ErrorAs(t, err, &callError)
+	require.Equal(t, testService, callError.Service)
+	require.Equal(t, "foo", callError.Operation)
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeResourceExhausted, handlerError.Type)
+
+	handle, err := client.NewHandle("bar", "id")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorAs(t, err, &callError)
+	require.Equal(t, "bar", callError.Operation)
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeNotImplemented, handlerError.Type)
+}
+
+type unavailableHandler struct {
+	UnimplementedHandler
+	guidance *RetryGuidance
+}
+
+func (h *unavailableHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return nil, &HandlerError{Type: HandlerErrorTypeUnavailable, Cause: errors.New("try again later"), RetryGuidance: h.guidance}
+}
+
+func TestHandlerError_RetryGuidance(t *testing.T) {
+	cases := []struct {
+		name     string
+		guidance *RetryGuidance
+	}{
+		{name: "unset", guidance: nil},
+		{name: "retry after only", guidance: &RetryGuidance{RetryAfter: time.Second}},
+		{name: "full", guidance: &RetryGuidance{RetryAfter: 2 * time.Second, MaxAttempts: 3, Jitter: 500 * time.Millisecond}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			ctx, client, teardown := setup(t, &unavailableHandler{guidance: c.guidance})
+			defer teardown()
+
+			_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+			var handlerError *HandlerError
+			require.ErrorAs(t, err, &handlerError)
+			require.Equal(t, HandlerErrorTypeUnavailable, handlerError.Type)
+			require.Equal(t, "try again later", handlerError.Cause.Error())
+			require.Equal(t, c.guidance, handlerError.RetryGuidance)
+		})
+	}
+}
+
+func TestHandlerError_StatusCodeMapping(t *testing.T) {
+	server := httptest.NewServer(NewHTTPHandler(HandlerOptions{
+		Handler: &unavailableHandler{},
+		StatusCodeFromHandlerErrorType: func(errorType HandlerErrorType) (int, bool) {
+			if errorType == HandlerErrorTypeUnavailable {
+				return http.StatusBadGateway, true
+			}
+			return DefaultStatusCodeFromHandlerErrorType(errorType)
+		},
+	}))
+	defer server.Close()
+
+	var statusCode int
+	client, err := NewHTTPClient(HTTPClientOptions{
+		BaseURL: server.URL,
+		Service: testService,
+		HTTPCaller: func(r *http.Request) (*http.Response, error) {
+			response, err := http.DefaultClient.Do(r)
+			if err == nil {
+				statusCode = response.StatusCode
+			}
+			return response, err
+		},
+	})
+	require.NoError(t, err)
+
+	// The default client mapping doesn't recognize the status.
+	_, err = client.StartOperation(context.Background(), "foo", nil, StartOperationOptions{})
+	require.Equal(t, http.StatusBadGateway, statusCode)
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+
+	client.options.HandlerErrorTypeFromStatusCode = func(statusCode int) (HandlerErrorType, bool) {
+		if statusCode == http.StatusBadGateway {
+			return HandlerErrorTypeUnavailable, true
+		}
+		return DefaultHandlerErrorTypeFromStatusCode(statusCode)
+	}
+	_, err = client.StartOperation(context.Background(), "foo", nil, StartOperationOptions{})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeUnavailable, handlerError.Type)
+	require.Equal(t, "try again later", handlerError.Cause.Error())
+}
+
+type lifecycleOperation struct {
+	UnimplementedOperation[NoValue, NoValue]
+	name          string
+	initializeErr error
+	closeErr      error
+	initialized   bool
+	initializeCtx context.Context
+	closed        bool
+}
+
+func (o *lifecycleOperation) Name() string {
+	return o.name
+}
+
+func (o *lifecycleOperation) Initialize(ctx context.Context) error {
+	o.initialized = true
+	o.initializeCtx = ctx
+	return o.initializeErr
+}
+
+func (o *lifecycleOperation) Close() error {
+	o.closed = true
+	return o.closeErr
+}
+
+func TestOperationLifecycle(t *testing.T) {
+	a := &lifecycleOperation{name: "a"}
+	b := &lifecycleOperation{name: "b", closeErr: errors.New("close failed")}
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(a, b, noValueOperation))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+	require.True(t, a.initialized)
+	require.True(t, b.initialized)
+	require.False(t, a.closed)
+
+	err = handler.Close(context.Background())
+	require.ErrorContains(t, err, `failed to close operation "b": close failed`)
+	require.True(t, a.closed)
+	require.True(t, b.closed)
+}
+
+func TestOperationLifecycle_SharedAcrossHandlers(t *testing.T) {
+	a := &lifecycleOperation{name: "a"}
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(a))
+	require.NoError(t, registry.Register(svc))
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	first, err := registry.NewHandlerWithContext(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "value", a.initializeCtx.Value(ctxKey{}))
+	a.initializeCtx = nil
+	second, err := registry.NewHandler()
+	require.NoError(t, err)
+	require.Nil(t, a.initializeCtx, "operations should be initialized once")
+
+	require.NoError(t, first.Close(context.Background()))
+	require.NoError(t, first.Close(context.Background()))
+	require.False(t, a.closed, "operations should stay open while a handler serves them")
+	require.NoError(t, second.Close(context.Background()))
+	require.True(t, a.closed)
+}
+
+func TestOperationLifecycle_RegisterAfterNewHandler(t *testing.T) {
+	a := &lifecycleOperation{name: "a"}
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(a))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	late := &lifecycleOperation{name: "late"}
+	require.ErrorContains(t, svc.Register(late), `operation "late" implements Initializer or Closer and cannot be registered while a handler serving service "`+testService+`" is open`)
+	require.ErrorContains(t, svc.RegisterWithOptions(late, OperationOptions{}), "cannot be registered")
+	require.Nil(t, svc.Operation("late"))
+	require.False(t, late.initialized)
+	require.NoError(t, svc.Register(noValueOperation))
+
+	// Unregistered operations are still closed by the handler that initialized them.
+	require.Equal(t, a, svc.Unregister("a"))
+	require.NoError(t, handler.Close(context.Background()))
+	require.True(t, a.closed)
+
+	// Once the handler is closed, the next handler initializes newly registered operations.
+	require.NoError(t, svc.Register(late))
+	handler, err = registry.NewHandler()
+	require.NoError(t, err)
+	require.True(t, late.initialized)
+	require.NoError(t, handler.Close(context.Background()))
+}
+
+func TestOperationLifecycle_InitializeError(t *testing.T) {
+	a := &lifecycleOperation{name: "a"}
+	b := &lifecycleOperation{name: "b", initializeErr: errors.New("initialize failed")}
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(a, b))
+	require.NoError(t, registry.Register(svc))
+	_, err := registry.NewHandler()
+	require.ErrorContains(t, err, `failed to initialize operation "b": initialize failed`)
+	require.True(t, a.closed)
+	require.False(t, b.closed)
+
+	// A failed handler does not keep the service from registering operations that need to be initialized.
+	require.NoError(t, svc.Register(&lifecycleOperation{name: "c"}))
+}
+
+func TestOperationContentTypes(t *testing.T) {
+	svc := NewService("svc")
+	require.NoError(t, svc.RegisterWithOptions(numberValidatorOperation, OperationOptions{
+		AcceptContentTypes:  []string{"application/json"},
+		ProduceContentTypes: []string{"application/json"},
+	}))
+	require.NoError(t, svc.RegisterWithOptions(bytesIOOperation, OperationOptions{
+		ProduceContentTypes: []string{"application/json"},
+	}))
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: handler})
+	send := func(operation, contentType, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("POST", "/svc/"+operation, strings.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		writer := httptest.NewRecorder()
+		httpHandler.ServeHTTP(writer, request)
+		return writer
+	}
+
+	writer := send("number-validator", "application/json; charset=utf-8", "3")
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "3", writer.Body.String())
+
+	writer = send("number-validator", "application/octet-stream", "3")
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.Contains(t, writer.Body.String(), "unsupported input content type")
+
+	// Inputs of any type are accepted but the []byte output is not of a produced type.
+	writer = send("bytes-io", "application/octet-stream", "hello")
+	require.Equal(t, http.StatusInternalServerError, writer.Code)
+}
+
+type dryRunOperation struct {
+	UnimplementedOperation[int, int]
+	started bool
+}
+
+func (h *dryRunOperation) Name() string {
+	return "dry-run"
+}
+
+func (h *dryRunOperation) Start(ctx context.Context, input int, options StartOperationOptions) (HandlerStartOperationResult[int], error) {
+	h.started = true
+	return &HandlerStartOperationResultSync[int]{Value: input}, nil
+}
+
+func (h *dryRunOperation) ValidateInput(ctx context.Context, input int, options StartOperationOptions) error {
+	if input < 0 {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "input must not be negative")
+	}
+	return nil
+}
+
+func TestStartOperation_DryRun(t *testing.T) {
+	op := &dryRunOperation{}
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(op, numberValidatorOperation))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := StartOperation(ctx, client, op, 3, StartOperationOptions{DryRun: true})
+	require.NoError(t, err)
+	require.True(t, result.DryRun)
+	require.Nil(t, result.Pending)
+	require.False(t, op.started)
+
+	_, err = StartOperation(ctx, client, op, -1, StartOperationOptions{DryRun: true})
+	var handlerErr *HandlerError
+	require.ErrorAs(t, err, &handlerErr)
+	require.Equal(t, HandlerErrorTypeBadRequest, handlerErr.Type)
+	require.False(t, op.started)
+
+	_, err = StartOperation(ctx, client, numberValidatorOperation, 3, StartOperationOptions{DryRun: true})
+	require.ErrorAs(t, err, &handlerErr)
+	require.Equal(t, HandlerErrorTypeNotImplemented, handlerErr.Type)
+
+	result, err = StartOperation(ctx, client, op, 3, StartOperationOptions{})
+	require.NoError(t, err)
+	require.False(t, result.DryRun)
+	require.Equal(t, 3, result.Successful)
+	require.True(t, op.started)
+}
+
+type syncLinksOperation struct {
+	UnimplementedOperation[int, int]
+}
+
+func (h *syncLinksOperation) Name() string {
+	return "sync-links"
+}
+
+func (h *syncLinksOperation) Start(ctx context.Context, input int, options StartOperationOptions) (HandlerStartOperationResult[int], error) {
+	return &HandlerStartOperationResultSync[int]{
+		Value: input,
+		Links: []Link{{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/result"}, Type: "result"}},
+	}, nil
+}
+
+func TestStartOperation_SyncLinks(t *testing.T) {
+	op := &syncLinksOperation{}
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(op))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := StartOperation(ctx, client, op, 3, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Successful)
+	require.Equal(t, []Link{{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/result"}, Type: "result"}}, result.Links)
+}
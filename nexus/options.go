@@ -24,11 +24,18 @@ type StartOperationOptions struct {
 	// asynchronous operation completes.
 	CallbackHeader Header
 	// Request ID that may be used by the server handler to dedupe a start request.
-	// By default a v4 UUID will be generated by the client.
+	// By default the client generates one with HTTPClientOptions.RequestIDGenerator, a v4 UUID unless customized.
 	RequestID string
 	// Links contain arbitrary caller information. Handlers may use these links as
 	// metadata on resources associated with and operation.
 	Links []Link
+	// DryRun requests that the handler only validates the request and its input without starting the operation.
+	//
+	// Handlers respond to a valid dry run with [HandlerStartOperationResultDryRun], which the client surfaces via
+	// [ClientStartOperationResult.DryRun], and fail it as they would a regular start request otherwise. Handlers and
+	// operations that don't support dry runs must fail with [HandlerErrorTypeNotImplemented]. Operations invoked via a
+	// handler constructed by [ServiceRegistry.NewHandler] opt in by implementing [InputValidator].
+	DryRun bool
 }
 
 // GetOperationResultOptions are options for the GetOperationResult client and server APIs.
@@ -44,6 +51,14 @@ type GetOperationResultOptions struct {
 	Wait time.Duration
 }
 
+// WaitOptions are options for [OperationHandle.Wait].
+type WaitOptions struct {
+	// Header to send with each get result request.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+}
+
 // GetOperationInfoOptions are options for the GetOperationInfo client and server APIs.
 type GetOperationInfoOptions struct {
 	// Header contains the request header fields either received by the server or to be sent by the client.
@@ -52,6 +67,12 @@ type GetOperationInfoOptions struct {
 	//
 	// Header values set here will overwrite any SDK-provided values for the same key.
 	Header Header
+	// The ETag of the operation information cached by the caller, from the If-None-Match request header. Only set in
+	// server methods, the client sends the ETag of the information last returned by a handle, see OperationInfo.ETag.
+	//
+	// Handlers may use it to skip work for unchanged operations; returning information with a matching ETag results in
+	// a 304 Not Modified response without a body.
+	IfNoneMatch string
 }
 
 // CancelOperationOptions are options for the CancelOperation client and server APIs.
@@ -63,3 +84,21 @@ type CancelOperationOptions struct {
 	// Header values set here will overwrite any SDK-provided values for the same key.
 	Header Header
 }
+
+// RegisterOperationCallbackOptions are options for the RegisterCallback client and server APIs.
+type RegisterOperationCallbackOptions struct {
+	// Header contains the request header fields either received by the server or to be sent by the client.
+	//
+	// Header will always be non empty in server methods and can be optionally set in the client API.
+	//
+	// Header values set here will overwrite any SDK-provided values for the same key.
+	Header Header
+	// CallbackURL is the URL the handler should deliver the operation's completion to, in addition to any
+	// previously registered callbacks. Required.
+	//
+	// Implement a [CompletionHandler] and expose it as an HTTP handler to handle async completions.
+	CallbackURL string
+	// Optional header fields set by a client that are required to be attached to the callback request when the
+	// operation completes.
+	CallbackHeader Header
+}
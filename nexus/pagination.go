@@ -0,0 +1,76 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoMorePages is returned by [Paginator.FetchNextPage] after the last page has been fetched.
+var ErrNoMorePages = errors.New("no more pages")
+
+// PageRequest is the input of a list-style operation that returns its results in pages.
+type PageRequest[I any] struct {
+	// Input to the operation, the same for all pages.
+	Input I `json:"input"`
+	// Token identifying the page to return, taken from [Page.NextToken] of the previous page. Empty for the first page.
+	PageToken string `json:"pageToken,omitempty"`
+}
+
+// Page is the output of a list-style operation that returns its results in pages.
+type Page[T any] struct {
+	// Items in this page.
+	Items []T `json:"items"`
+	// Token to request the next page with. Empty if this is the last page.
+	NextToken string `json:"nextToken,omitempty"`
+}
+
+// Paginator fetches the pages of a list-style operation one at a time. Create with [Paginate].
+type Paginator[I, T any] struct {
+	client    *HTTPClient
+	operation OperationReference[PageRequest[I], Page[T]]
+	input     I
+	options   ExecuteOperationOptions
+	nextToken string
+	done      bool
+}
+
+// Paginate returns a [Paginator] that executes the given operation once per page, passing the token returned by the
+// previous page along with the input. A RequestID set in options only applies to the first page, since each page is
+// fetched by a separate request, later pages use generated request IDs.
+//
+//	ref := NewOperationReference[PageRequest[MyQuery], Page[MyItem]]("list-items")
+//	paginator := Paginate(client, ref, MyQuery{}, ExecuteOperationOptions{})
+//	for paginator.HasNextPage() {
+//		items, err := paginator.FetchNextPage(ctx)
+//		...
+//	}
+func Paginate[I, T any](client *HTTPClient, operation OperationReference[PageRequest[I], Page[T]], input I, options ExecuteOperationOptions) *Paginator[I, T] {
+	return &Paginator[I, T]{
+		client:    client,
+		operation: operation,
+		input:     input,
+		options:   options,
+	}
+}
+
+// HasNextPage reports whether there are more pages to fetch.
+func (p *Paginator[I, T]) HasNextPage() bool {
+	return !p.done
+}
+
+// FetchNextPage executes the operation to fetch the next page and returns its items. Returns [ErrNoMorePages] if the
+// last page has already been fetched. A failed fetch may be retried by calling FetchNextPage again.
+func (p *Paginator[I, T]) FetchNextPage(ctx context.Context) ([]T, error) {
+	if p.done {
+		return nil, ErrNoMorePages
+	}
+	page, err := ExecuteOperation(ctx, p.client, p.operation, PageRequest[I]{Input: p.input, PageToken: p.nextToken}, p.options)
+	if err != nil {
+		return nil, err
+	}
+	p.nextToken = page.NextToken
+	p.done = page.NextToken == ""
+	// Retries of the first page reuse the caller's request ID, later pages must not.
+	p.options.RequestID = ""
+	return page.Items, nil
+}
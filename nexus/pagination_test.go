@@ -0,0 +1,56 @@
+package nexus
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var listNumbersOperation = NewSyncOperation("list-numbers", func(ctx context.Context, input PageRequest[int], options StartOperationOptions) (Page[int], error) {
+	const pageSize = 2
+	if input.PageToken != "" && options.RequestID == "first-page" {
+		return Page[int]{}, HandlerErrorf(HandlerErrorTypeBadRequest, "request ID reused across pages")
+	}
+	start := 0
+	if input.PageToken != "" {
+		var err error
+		if start, err = strconv.Atoi(input.PageToken); err != nil {
+			return Page[int]{}, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid page token")
+		}
+	}
+	end := min(start+pageSize, input.Input)
+	page := Page[int]{}
+	for i := start; i < end; i++ {
+		page.Items = append(page.Items, i)
+	}
+	if end < input.Input {
+		page.NextToken = strconv.Itoa(end)
+	}
+	return page, nil
+})
+
+func TestPaginate(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(listNumbersOperation))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	paginator := Paginate(client, NewOperationReference[PageRequest[int], Page[int]]("list-numbers"), 5, ExecuteOperationOptions{RequestID: "first-page"})
+	var pages [][]int
+	for paginator.HasNextPage() {
+		items, err := paginator.FetchNextPage(ctx)
+		require.NoError(t, err)
+		pages = append(pages, items)
+	}
+	require.Equal(t, [][]int{{0, 1}, {2, 3}, {4}}, pages)
+
+	_, err = paginator.FetchNextPage(ctx)
+	require.ErrorIs(t, err, ErrNoMorePages)
+}
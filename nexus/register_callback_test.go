@@ -0,0 +1,88 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type asyncWithCallbacksHandler struct {
+	UnimplementedHandler
+	callbacks []RegisterOperationCallbackOptions
+}
+
+func (h *asyncWithCallbacksHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultAsync{
+		OperationID: "a/sync",
+	}, nil
+}
+
+func (h *asyncWithCallbacksHandler) RegisterOperationCallback(ctx context.Context, service, operation, operationID string, options RegisterOperationCallbackOptions) error {
+	if service != testService {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "unexpected service: %s", service)
+	}
+	if operation != "f/o/o" {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation to be 'f/o/o', got: %s", operation)
+	}
+	if operationID != "a/sync" {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "expected operation ID to be 'a/sync', got: %s", operationID)
+	}
+	if options.Header.Get("nexus-callback-callback-test") != "" {
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "callback header not omitted from options Header")
+	}
+	if len(h.callbacks) == 1 {
+		return HandlerErrorf(HandlerErrorTypeResourceExhausted, "too many callbacks")
+	}
+	h.callbacks = append(h.callbacks, options)
+	return nil
+}
+
+func TestRegisterCallback(t *testing.T) {
+	handler := &asyncWithCallbacksHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "f/o/o", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.NotNil(t, handle)
+
+	err = handle.RegisterCallback(ctx, RegisterOperationCallbackOptions{})
+	require.ErrorContains(t, err, "empty CallbackURL")
+
+	err = handle.RegisterCallback(ctx, RegisterOperationCallbackOptions{
+		Header:         Header{"foo": "bar"},
+		CallbackURL:    "http://test/callback?a=b",
+		CallbackHeader: Header{"callback-test": "ok"},
+	})
+	require.NoError(t, err)
+	require.Len(t, handler.callbacks, 1)
+	require.Equal(t, "http://test/callback?a=b", handler.callbacks[0].CallbackURL)
+	require.Equal(t, "ok", handler.callbacks[0].CallbackHeader.Get("callback-test"))
+	require.Equal(t, "bar", handler.callbacks[0].Header.Get("foo"))
+
+	err = handle.RegisterCallback(ctx, RegisterOperationCallbackOptions{CallbackURL: "http://test/other"})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeResourceExhausted, handlerError.Type)
+}
+
+func TestRegisterCallback_NotImplemented(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(asyncNumberValidatorOperationInstance))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := NewHandle(client, asyncNumberValidatorOperationInstance, "1")
+	require.NoError(t, err)
+	err = handle.RegisterCallback(ctx, RegisterOperationCallbackOptions{CallbackURL: "http://test/callback"})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeNotImplemented, handlerError.Type)
+}
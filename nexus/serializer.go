@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"slices"
+	"strings"
 )
 
 // A Reader is a container for a [Header] and an [io.Reader].
@@ -28,20 +31,28 @@ type Content struct {
 	Header Header
 	// Data contains request or response data. May be nil for empty data.
 	Data []byte
+	// Metadata is free-form information attached to a result returned from [Handler.GetOperationResult] or
+	// [Operation.GetResult], not interpreted by serializers. Transmitted as HTTP headers prefixed with
+	// "Nexus-Result-Metadata-" and surfaced by [GetOperationResultWithDetails]. Ignored in all other contexts.
+	Metadata Header
 }
 
 // A LazyValue holds a value encoded in an underlying [Reader].
 //
-// ⚠️ When a LazyValue is returned from a client - if directly accessing the [Reader] - it must be read it in its
-// entirety and closed to free up the associated HTTP connection. Otherwise the [LazyValue.Consume] method must be
-// called.
+// ⚠️ When a LazyValue is returned from a client - if directly accessing the [Reader] or using [LazyValue.Stream] - it
+// must be read it in its entirety and closed to free up the associated HTTP connection. Otherwise the
+// [LazyValue.Consume] or [LazyValue.WriteTo] method must be called.
 //
 // ⚠️ When a LazyValue is passed to a server handler, it must not be used after the returning from the handler method.
 type LazyValue struct {
 	serializer Serializer
 	Reader     *Reader
+	closeErr   error
+	consumed   bool
 }
 
+var errValueAlreadyConsumed = errors.New("value already consumed")
+
 // Create a new [LazyValue] from a given serializer and reader.
 func NewLazyValue(serializer Serializer, reader *Reader) *LazyValue {
 	return &LazyValue{
@@ -55,16 +66,138 @@ func NewLazyValue(serializer Serializer, reader *Reader) *LazyValue {
 //
 //	var v int
 //	err := lazyValue.Consume(&v)
-func (l *LazyValue) Consume(v any) error {
-	defer l.Reader.Close()
-	data, err := io.ReadAll(l.Reader)
+//
+// Deserialization errors are returned as a [*DeserializeError]. Closing the underlying reader may fail, e.g. when the
+// body was truncated. If it does, the close error is joined with any read or deserialization error. A close error
+// after the value was successfully deserialized is not returned, and is only available via CloseError.
+func (l *LazyValue) Consume(v any) (err error) {
+	if l.consumed {
+		return errValueAlreadyConsumed
+	}
+	l.consumed = true
+	defer func() { err = l.close(err) }()
+	var reader io.Reader = l.Reader
+	if s, ok := l.serializer.(sizeLimitedSerializer); ok {
+		if limit := s.maxContentSize(l.Reader.Header); limit > 0 {
+			// Read one extra byte for the serializer to detect oversized content without buffering it entirely.
+			if limit < math.MaxInt64 {
+				limit++
+			}
+			reader = io.LimitReader(reader, limit)
+		}
+	}
+	data, err := io.ReadAll(reader)
 	if err != nil {
 		return err
 	}
-	return l.serializer.Deserialize(&Content{
-		Header: l.Reader.Header,
-		Data:   data,
-	}, v)
+	if err := l.serializer.Deserialize(&Content{Header: l.Reader.Header, Data: data}, v); err != nil {
+		return newDeserializeError(l.Reader.Header.Get("type"), v, err)
+	}
+	return nil
+}
+
+// DeserializeError is returned by [LazyValue.Consume] when content fails to deserialize. Use Unsupported to
+// distinguish content the serializer doesn't support from malformed content of a supported type.
+type DeserializeError struct {
+	// Content type of the content, empty if the content has no type.
+	ContentType string
+	// Type of the value the content was deserialized into.
+	Type reflect.Type
+	// Set if no serializer supports deserializing the content type into Type.
+	Unsupported bool
+	// Names of the serializers tried, in order, when the content was rejected by a serializer created with
+	// [DefaultSerializer] or [NewDefaultSerializer], which try a chain of serializers. Empty otherwise.
+	Tried []string
+	// The error returned by the [Serializer].
+	Cause error
+}
+
+func newDeserializeError(contentType string, v any, cause error) error {
+	var deserializeError *DeserializeError
+	if errors.As(cause, &deserializeError) {
+		return cause
+	}
+	var tried []string
+	var chainError *chainDeserializeError
+	if errors.As(cause, &chainError) {
+		tried = chainError.tried
+	}
+	return &DeserializeError{
+		ContentType: contentType,
+		Type:        reflect.TypeOf(v),
+		Unsupported: errors.Is(cause, errSerializerIncompatible),
+		Tried:       tried,
+		Cause:       cause,
+	}
+}
+
+// Error implements the error interface.
+func (e *DeserializeError) Error() string {
+	if e.Unsupported {
+		return fmt.Sprintf("unsupported content type %q for %v: %v", e.ContentType, e.Type, e.Cause)
+	}
+	return fmt.Sprintf("failed to deserialize content of type %q into %v: %v", e.ContentType, e.Type, e.Cause)
+}
+
+// Unwrap returns the cause for use with utilities in the errors package.
+func (e *DeserializeError) Unwrap() error {
+	return e.Cause
+}
+
+// CloseError returns the error encountered closing the underlying reader when the value was consumed with Consume or
+// WriteTo, if any.
+func (l *LazyValue) CloseError() error {
+	return l.closeErr
+}
+
+// close closes the underlying reader and joins any close error with err, if err is not nil.
+func (l *LazyValue) close(err error) error {
+	if l.Reader.ReadCloser == nil {
+		return err
+	}
+	l.closeErr = l.Reader.Close()
+	if l.closeErr == nil || err == nil {
+		return err
+	}
+	return errors.Join(err, l.closeErr)
+}
+
+// WriteTo consumes the lazy value, copying the raw data of the underlying [Reader] to w without deserializing it, and
+// closes the reader. Useful for streaming large results to a file or network connection.
+//
+// WriteTo implements [io.WriterTo]. The value must not be used after calling this method.
+//
+// As with Consume, an error closing the underlying reader is joined with any copy error, and is otherwise only
+// available via CloseError.
+func (l *LazyValue) WriteTo(w io.Writer) (n int64, err error) {
+	if l.consumed {
+		return 0, errValueAlreadyConsumed
+	}
+	l.consumed = true
+	if l.Reader.ReadCloser == nil {
+		return 0, nil
+	}
+	defer func() { err = l.close(err) }()
+	return io.Copy(w, l.Reader.ReadCloser)
+}
+
+var _ io.WriterTo = &LazyValue{}
+
+// Stream consumes the lazy value, returning the raw data of the underlying [Reader] as a stream along with the
+// [Header] describing it, without buffering or deserializing it. Useful for processing large results, e.g.
+// application/octet-stream content, incrementally.
+//
+// The caller owns the returned stream and must read it in its entirety and close it. The value must not be used after
+// calling this method; Consume, WriteTo and Stream return an error if the value was already consumed.
+func (l *LazyValue) Stream() (io.ReadCloser, Header, error) {
+	if l.consumed {
+		return nil, nil, errValueAlreadyConsumed
+	}
+	l.consumed = true
+	if l.Reader.ReadCloser == nil {
+		return io.NopCloser(strings.NewReader("")), l.Reader.Header, nil
+	}
+	return l.Reader.ReadCloser, l.Reader.Header, nil
 }
 
 // Serializer is used by the framework to serialize/deserialize input and output.
@@ -78,6 +211,20 @@ type Serializer interface {
 	Deserialize(*Content, any) error
 }
 
+// StreamSerializer is an optional interface a [Serializer] may implement to encode values as a stream rather than an
+// in-memory [Content], avoiding buffering large payloads. When the configured serializer implements it, the client
+// streams start operation inputs and handlers created with [NewHTTPHandler] stream operation results using
+// SerializeStream instead of Serialize. Deserialization always uses Deserialize.
+type StreamSerializer interface {
+	Serializer
+	// SerializeStream encodes a value into a reader and the [Header] describing its content. Since the length of a
+	// stream is generally unknown, the header need not include a "length" key.
+	//
+	// The framework takes ownership of the returned reader and closes it once the data has been sent, or on error.
+	// Implementations must release any resources backing the stream when it is closed.
+	SerializeStream(any) (io.ReadCloser, Header, error)
+}
+
 // FailureConverter is used by the framework to transform [error] instances to and from [Failure] instances.
 // To customize conversion logic, implement this interface and provide your implementation to framework methods such as
 // [NewClient] and [NewHTTPHandler].
@@ -87,7 +234,7 @@ type FailureConverter interface {
 	// Implementors should take a best-effort approach and never fail this method.
 	// Note that the provided error may be nil.
 	ErrorToFailure(error) Failure
-	// ErrorToFailure converts a [Failure] to an [error].
+	// FailureToError converts a [Failure] to an [error].
 	// Implementors should take a best-effort approach and never fail this method.
 	FailureToError(Failure) error
 }
@@ -109,7 +256,7 @@ func (c serializerChain) Serialize(v any) (*Content, error) {
 		}
 		return p, nil
 	}
-	return nil, errSerializerIncompatible
+	return nil, fmt.Errorf("%w: no serializer could serialize value of type %T (tried %s)", errSerializerIncompatible, v, strings.Join(c.names(false), ", "))
 }
 
 func (c serializerChain) Deserialize(content *Content, v any) error {
@@ -124,20 +271,90 @@ func (c serializerChain) Deserialize(content *Content, v any) error {
 		}
 		return nil
 	}
+	return &chainDeserializeError{contentType: content.Header["type"], tried: c.names(true)}
+}
+
+// names describes the serializers in the chain, in the order they were tried, for error messages. Only called on
+// failure to keep the happy path allocation free.
+func (c serializerChain) names(reverse bool) []string {
+	names := make([]string, len(c))
+	for i, l := range c {
+		names[i] = fmt.Sprintf("%T", l)
+	}
+	if reverse {
+		slices.Reverse(names)
+	}
+	return names
+}
+
+// chainDeserializeError is returned by a serializerChain when none of its serializers support the content.
+type chainDeserializeError struct {
+	contentType string
+	tried       []string
+}
+
+func (e *chainDeserializeError) Error() string {
+	return fmt.Sprintf("%v: no serializer could deserialize content of type %q (tried %s)", errSerializerIncompatible, e.contentType, strings.Join(e.tried, ", "))
+}
+
+func (e *chainDeserializeError) Unwrap() error {
 	return errSerializerIncompatible
 }
 
 var _ Serializer = serializerChain{}
 
-type jsonSerializer struct{}
+type jsonSerializer struct {
+	// Max nesting depth of deserialized content, unlimited if not positive.
+	maxDepth int
+}
 
-func (jsonSerializer) Deserialize(c *Content, v any) error {
+var errJSONMaxDepthExceeded = errors.New("JSON nesting depth exceeds limit")
+
+func (s jsonSerializer) Deserialize(c *Content, v any) error {
 	if !isMediaTypeJSON(c.Header["type"]) {
 		return errSerializerIncompatible
 	}
+	if s.maxDepth > 0 {
+		if err := checkJSONDepth(c.Data, s.maxDepth); err != nil {
+			return err
+		}
+	}
 	return json.Unmarshal(c.Data, &v)
 }
 
+// checkJSONDepth scans data and returns an error if objects and arrays are nested deeper than maxDepth. Malformed data
+// is left for the decoder to reject.
+func checkJSONDepth(data []byte, maxDepth int) error {
+	depth := 0
+	inString := false
+	escaped := false
+	for _, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("%w: max depth is %d", errJSONMaxDepthExceeded, maxDepth)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
+
 func (jsonSerializer) Serialize(v any) (*Content, error) {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -247,6 +464,145 @@ func DefaultSerializer() Serializer {
 	return defaultSerializer
 }
 
+// SerializerKind identifies one of the SDK's built-in serializers, see [NewDefaultSerializer].
+type SerializerKind int
+
+const (
+	// SerializerKindNil handles nil values and empty content.
+	SerializerKindNil SerializerKind = iota + 1
+	// SerializerKindByteSlice handles byte slices as application/octet-stream content.
+	SerializerKindByteSlice
+	// SerializerKindJSON handles JSONables as application/json content.
+	SerializerKindJSON
+)
+
+// DefaultSerializerOptions are options for [NewDefaultSerializer].
+type DefaultSerializerOptions struct {
+	// The built-in serializers to compose, in order. Kinds that are omitted are disabled.
+	// Defaults to nil, byte slice, JSON; the order used by [DefaultSerializer].
+	//
+	// Values are serialized with the first serializer in the order that supports them. Content is deserialized in
+	// reverse order, with the last serializer that supports it. For instance, placing SerializerKindJSON before
+	// SerializerKindByteSlice serializes byte slices as JSON, while application/octet-stream content is still
+	// deserialized into byte slices.
+	Order []SerializerKind
+	// Maximum nesting depth of objects and arrays in JSON content accepted by the JSON serializer. Deeper content fails
+	// to deserialize, protecting handlers that accept untrusted input from excessive resource consumption. Handlers
+	// created from a [ServiceRegistry] respond to operation inputs that fail to deserialize with a
+	// [HandlerErrorTypeBadRequest] error.
+	// Unlimited by default. 64 is a generous limit for most payloads.
+	MaxJSONDepth int
+}
+
+// NewDefaultSerializer composes the SDK's built-in serializers as configured by the given options.
+// Returns an error if the order contains an unknown or duplicate kind.
+func NewDefaultSerializer(options DefaultSerializerOptions) (Serializer, error) {
+	order := options.Order
+	if len(order) == 0 {
+		order = []SerializerKind{SerializerKindNil, SerializerKindByteSlice, SerializerKindJSON}
+	}
+	chain := make(serializerChain, 0, len(order))
+	for i, kind := range order {
+		if slices.Contains(order[:i], kind) {
+			return nil, fmt.Errorf("duplicate serializer kind: %d", kind)
+		}
+		switch kind {
+		case SerializerKindNil:
+			chain = append(chain, nilSerializer{})
+		case SerializerKindByteSlice:
+			chain = append(chain, byteSliceSerializer{})
+		case SerializerKindJSON:
+			chain = append(chain, jsonSerializer{maxDepth: options.MaxJSONDepth})
+		default:
+			return nil, fmt.Errorf("unknown serializer kind: %d", kind)
+		}
+	}
+	return compositeSerializer{chain}, nil
+}
+
+// ErrContentTooLarge is returned when deserializing content that exceeds the max size of a serializer created with
+// [NewLimitedSerializer].
+var ErrContentTooLarge = errors.New("content exceeds max size")
+
+// NewLimitedSerializer wraps a [Serializer], failing to deserialize content larger than maxSize bytes with an error
+// wrapping [ErrContentTooLarge]. Serialization is delegated to inner, which remains a [StreamSerializer] if it is one.
+// Wrapping a [NegotiatingSerializer] retains content negotiation, and registering limited serializers with a
+// [NegotiatingSerializer] limits content of their media types.
+//
+// The limit applies to the size of content after any transport level decoding, such as decompression, and is
+// independent of transport level body size limits. [LazyValue.Consume] reads at most maxSize+1 bytes of the underlying
+// stream, protecting handlers that accept untrusted input from excessive memory consumption. Handlers created from a
+// [ServiceRegistry] respond to operation inputs that exceed the limit with a [HandlerErrorTypeBadRequest] error.
+//
+// Returns inner as is if maxSize is not positive.
+func NewLimitedSerializer(inner Serializer, maxSize int64) Serializer {
+	if maxSize <= 0 {
+		return inner
+	}
+	limited := limitedSerializer{inner, maxSize}
+	if s, ok := inner.(StreamSerializer); ok {
+		return limitedStreamSerializer{limited, s}
+	}
+	return limited
+}
+
+// sizeLimitedSerializer is implemented by serializers that limit the size of content they deserialize, to allow
+// limiting reads before content is passed to them.
+type sizeLimitedSerializer interface {
+	// maxContentSize returns the max size of content with the given header, 0 if unlimited.
+	maxContentSize(header Header) int64
+}
+
+type limitedSerializer struct {
+	inner   Serializer
+	maxSize int64
+}
+
+func (s limitedSerializer) Serialize(v any) (*Content, error) {
+	return s.inner.Serialize(v)
+}
+
+func (s limitedSerializer) Deserialize(c *Content, v any) error {
+	if int64(len(c.Data)) > s.maxSize {
+		return fmt.Errorf("%w of %d bytes", ErrContentTooLarge, s.maxSize)
+	}
+	return s.inner.Deserialize(c, v)
+}
+
+func (s limitedSerializer) maxContentSize(Header) int64 {
+	return s.maxSize
+}
+
+func (s limitedSerializer) accept() string {
+	if n, ok := s.inner.(negotiator); ok {
+		return n.accept()
+	}
+	return ""
+}
+
+func (s limitedSerializer) negotiate(accept []string) Serializer {
+	if n, ok := s.inner.(negotiator); ok {
+		if negotiated := n.negotiate(accept); negotiated != nil {
+			return NewLimitedSerializer(negotiated, s.maxSize)
+		}
+	}
+	return nil
+}
+
+var _ Serializer = limitedSerializer{}
+var _ negotiator = limitedSerializer{}
+
+type limitedStreamSerializer struct {
+	limitedSerializer
+	stream StreamSerializer
+}
+
+func (s limitedStreamSerializer) SerializeStream(v any) (io.ReadCloser, Header, error) {
+	return s.stream.SerializeStream(v)
+}
+
+var _ StreamSerializer = limitedStreamSerializer{}
+
 type failureErrorFailureConverter struct{}
 
 // ErrorToFailure implements FailureConverter.
@@ -276,3 +632,13 @@ var defaultFailureConverter FailureConverter = failureErrorFailureConverter{}
 func DefaultFailureConverter() FailureConverter {
 	return defaultFailureConverter
 }
+
+// ErrorToFailure converts an [error] to a [Failure] using the [DefaultFailureConverter].
+func ErrorToFailure(err error) Failure {
+	return defaultFailureConverter.ErrorToFailure(err)
+}
+
+// FailureToError converts a [Failure] to an [error] using the [DefaultFailureConverter].
+func FailureToError(f Failure) error {
+	return defaultFailureConverter.FailureToError(f)
+}
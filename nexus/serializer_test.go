@@ -1,9 +1,18 @@
 package nexus
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -106,6 +115,212 @@ func TestDefaultSerializer(t *testing.T) {
 	require.Equal(t, nil, a)
 }
 
+func TestDefaultSerializer_IncompatibleError(t *testing.T) {
+	var v int
+	err := defaultSerializer.Deserialize(&Content{Header: Header{"type": "application/x-unknown"}, Data: []byte("x")}, &v)
+	require.ErrorIs(t, err, errSerializerIncompatible)
+	require.ErrorContains(t, err, `content of type "application/x-unknown"`)
+	require.ErrorContains(t, err, "tried nexus.jsonSerializer, nexus.byteSliceSerializer, nexus.nilSerializer")
+
+	_, err = serializerChain{nilSerializer{}}.Serialize(1)
+	require.ErrorIs(t, err, errSerializerIncompatible)
+	require.ErrorContains(t, err, "value of type int (tried nexus.nilSerializer)")
+}
+
+func TestNewDefaultSerializer(t *testing.T) {
+	s, err := NewDefaultSerializer(DefaultSerializerOptions{})
+	require.NoError(t, err)
+	require.Equal(t, defaultSerializer, s)
+
+	// JSON before byte slice, byte slices are serialized as JSON.
+	s, err = NewDefaultSerializer(DefaultSerializerOptions{
+		Order: []SerializerKind{SerializerKindNil, SerializerKindJSON, SerializerKindByteSlice},
+	})
+	require.NoError(t, err)
+	c, err := s.Serialize([]byte("abc"))
+	require.NoError(t, err)
+	require.Equal(t, "application/json", c.Header["type"])
+	var b []byte
+	require.NoError(t, s.Deserialize(c, &b))
+	require.Equal(t, []byte("abc"), b)
+
+	// Byte slice disabled.
+	s, err = NewDefaultSerializer(DefaultSerializerOptions{
+		Order: []SerializerKind{SerializerKindNil, SerializerKindJSON},
+	})
+	require.NoError(t, err)
+	err = s.Deserialize(&Content{Header: Header{"type": "application/octet-stream"}, Data: []byte("abc")}, &b)
+	require.ErrorIs(t, err, errSerializerIncompatible)
+
+	_, err = NewDefaultSerializer(DefaultSerializerOptions{Order: []SerializerKind{SerializerKindJSON, SerializerKindJSON}})
+	require.ErrorContains(t, err, "duplicate serializer kind")
+	_, err = NewDefaultSerializer(DefaultSerializerOptions{Order: []SerializerKind{0}})
+	require.ErrorContains(t, err, "unknown serializer kind")
+}
+
+func TestJSONSerializer_MaxDepth(t *testing.T) {
+	s, err := NewDefaultSerializer(DefaultSerializerOptions{MaxJSONDepth: 3})
+	require.NoError(t, err)
+	content := func(data string) *Content {
+		return &Content{Header: Header{"type": "application/json"}, Data: []byte(data)}
+	}
+
+	var v any
+	require.NoError(t, s.Deserialize(content(`{"a":[{"b":"[[[[{{{{\"]]]"}]}`), &v))
+	require.NoError(t, s.Deserialize(content(`[[[1]],[[2]]]`), &v))
+
+	err = s.Deserialize(content(`[[[[1]]]]`), &v)
+	require.ErrorIs(t, err, errJSONMaxDepthExceeded)
+	require.ErrorContains(t, err, "max depth is 3")
+
+	// Unlimited by default.
+	require.NoError(t, defaultSerializer.Deserialize(content(strings.Repeat("[", 1000)+strings.Repeat("]", 1000)), &v))
+}
+
+func TestMaxJSONDepth_BadRequest(t *testing.T) {
+	serializer, err := NewDefaultSerializer(DefaultSerializerOptions{MaxJSONDepth: 2})
+	require.NoError(t, err)
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(NewSyncOperation("any", func(ctx context.Context, input any, options StartOperationOptions) (any, error) {
+		return input, nil
+	})))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setupCustom(t, handler, serializer, nil)
+	defer teardown()
+
+	_, err = client.ExecuteOperation(ctx, "any", [][]int{{1}}, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	_, err = client.ExecuteOperation(ctx, "any", [][][]int{{{1}}}, ExecuteOperationOptions{})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeBadRequest, handlerError.Type)
+}
+
+type countingReader struct {
+	io.Reader
+	read int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += n
+	return n, err
+}
+
+func TestLimitedSerializer(t *testing.T) {
+	s := NewLimitedSerializer(DefaultSerializer(), 16)
+	content := func(data string) *Content {
+		return &Content{Header: Header{"type": "application/json"}, Data: []byte(data)}
+	}
+
+	var v string
+	require.NoError(t, s.Deserialize(content(`"small"`), &v))
+	require.Equal(t, "small", v)
+	err := s.Deserialize(content(`"way too large to deserialize"`), &v)
+	require.ErrorIs(t, err, ErrContentTooLarge)
+	require.EqualError(t, err, "content exceeds max size of 16 bytes")
+
+	// Consume stops reading oversized content once the limit is exceeded.
+	reader := &countingReader{Reader: strings.NewReader(`"` + strings.Repeat("a", 1024*1024) + `"`)}
+	value := NewLazyValue(s, &Reader{io.NopCloser(reader), Header{"type": "application/json"}})
+	require.ErrorIs(t, value.Consume(&v), ErrContentTooLarge)
+	require.Equal(t, 17, reader.read)
+
+	// The limit may be as large as an int64 without overflowing.
+	value = NewLazyValue(NewLimitedSerializer(DefaultSerializer(), math.MaxInt64), &Reader{io.NopCloser(strings.NewReader(`"small"`)), Header{"type": "application/json"}})
+	require.NoError(t, value.Consume(&v))
+	require.Equal(t, "small", v)
+
+	// Stream serializers remain stream serializers.
+	_, ok := NewLimitedSerializer(&streamingSerializer{}, 16).(StreamSerializer)
+	require.True(t, ok)
+	_, ok = s.(StreamSerializer)
+	require.False(t, ok)
+	require.Equal(t, DefaultSerializer(), NewLimitedSerializer(DefaultSerializer(), 0))
+}
+
+func TestLimitedSerializer_BadRequest(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(NewSyncOperation("echo", func(ctx context.Context, input string, options StartOperationOptions) (string, error) {
+		return input, nil
+	})))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setupCustom(t, handler, NewLimitedSerializer(DefaultSerializer(), 1024), nil)
+	defer teardown()
+
+	_, err = client.ExecuteOperation(ctx, "echo", "small", ExecuteOperationOptions{})
+	require.NoError(t, err)
+	_, err = client.ExecuteOperation(ctx, "echo", strings.Repeat("a", 2048), ExecuteOperationOptions{})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeBadRequest, handlerError.Type)
+}
+
+func TestDeserializeError(t *testing.T) {
+	value := NewLazyValue(defaultSerializer, &Reader{
+		io.NopCloser(strings.NewReader("<ok/>")),
+		Header{"type": "application/xml"},
+	})
+	var out int
+	err := value.Consume(&out)
+	var deserializeError *DeserializeError
+	require.ErrorAs(t, err, &deserializeError)
+	require.True(t, deserializeError.Unsupported)
+	require.Equal(t, "application/xml", deserializeError.ContentType)
+	require.Equal(t, reflect.TypeOf(&out), deserializeError.Type)
+	require.ErrorIs(t, err, errSerializerIncompatible)
+	require.ErrorContains(t, err, `unsupported content type "application/xml" for *int`)
+	require.Equal(t, []string{"nexus.jsonSerializer", "nexus.byteSliceSerializer", "nexus.nilSerializer"}, deserializeError.Tried)
+
+	value = NewLazyValue(defaultSerializer, &Reader{
+		io.NopCloser(strings.NewReader("{")),
+		Header{"type": "application/json"},
+	})
+	err = value.Consume(&out)
+	require.ErrorAs(t, err, &deserializeError)
+	require.False(t, deserializeError.Unsupported)
+	require.Equal(t, "application/json", deserializeError.ContentType)
+	require.ErrorContains(t, err, `failed to deserialize content of type "application/json" into *int`)
+	require.Empty(t, deserializeError.Tried)
+}
+
+func TestDeserializeError_BadRequest(t *testing.T) {
+	registry := NewServiceRegistry()
+	svc := NewService(testService)
+	require.NoError(t, svc.Register(numberValidatorOperation))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	var handlerError *HandlerError
+	_, err = client.ExecuteOperation(ctx, numberValidatorOperation.Name(), &Content{
+		Header: Header{"type": "application/xml"},
+		Data:   []byte("<number>1</number>"),
+	}, ExecuteOperationOptions{})
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeBadRequest, handlerError.Type)
+	require.ErrorContains(t, err, `unsupported input content type "application/xml"`)
+
+	_, err = client.ExecuteOperation(ctx, numberValidatorOperation.Name(), &Content{
+		Header: Header{"type": "application/json"},
+		Data:   []byte("{"),
+	}, ExecuteOperationOptions{})
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeBadRequest, handlerError.Type)
+	require.ErrorContains(t, err, "invalid input")
+}
+
 // There's zero chance of concurrent updates in the test where this is used. Don't bother locking.
 type customSerializer struct {
 	encoded int
@@ -161,6 +376,87 @@ func TestCustomSerializer(t *testing.T) {
 	require.Equal(t, 4, c.encoded)
 }
 
+type streamingSerializer struct {
+	jsonSerializer
+	streamed atomic.Int32
+	closed   atomic.Int32
+}
+
+func (s *streamingSerializer) SerializeStream(v any) (io.ReadCloser, Header, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.streamed.Add(1)
+	return &closeCountingReader{Reader: bytes.NewReader(data), closed: &s.closed}, Header{"type": "application/json"}, nil
+}
+
+type closeCountingReader struct {
+	io.Reader
+	closed *atomic.Int32
+	once   sync.Once
+}
+
+func (r *closeCountingReader) Close() error {
+	r.once.Do(func() { r.closed.Add(1) })
+	return nil
+}
+
+func TestStreamSerializer(t *testing.T) {
+	svc := NewService(testService)
+	registry := NewServiceRegistry()
+	require.NoError(t, svc.Register(numberValidatorOperation))
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	s := &streamingSerializer{}
+	ctx, client, teardown := setupCustom(t, handler, s, nil)
+	defer teardown()
+
+	result, err := ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+
+	// Both the input and the result are streamed.
+	require.Equal(t, int32(2), s.streamed.Load())
+	require.Equal(t, int32(2), s.closed.Load())
+}
+
+type failingCloseReader struct {
+	io.Reader
+}
+
+func (failingCloseReader) Close() error {
+	return errors.New("truncated")
+}
+
+func TestLazyValue_CloseError(t *testing.T) {
+	value := NewLazyValue(defaultSerializer, &Reader{
+		failingCloseReader{strings.NewReader(`"ok"`)},
+		Header{"type": "application/json"},
+	})
+	var out string
+	require.NoError(t, value.Consume(&out))
+	require.Equal(t, "ok", out)
+	require.EqualError(t, value.CloseError(), "truncated")
+
+	value = NewLazyValue(defaultSerializer, &Reader{
+		failingCloseReader{strings.NewReader(`invalid`)},
+		Header{"type": "application/json"},
+	})
+	err := value.Consume(&out)
+	require.ErrorContains(t, err, "invalid character")
+	require.ErrorContains(t, err, "truncated")
+
+	value = NewLazyValue(defaultSerializer, &Reader{
+		io.NopCloser(strings.NewReader(`"ok"`)),
+		Header{"type": "application/json"},
+	})
+	require.NoError(t, value.Consume(&out))
+	require.NoError(t, value.CloseError())
+}
+
 func TestDefaultFailureConverterArbitraryError(t *testing.T) {
 	sourceErr := errors.New("test")
 	var f Failure
@@ -187,6 +483,13 @@ func TestDefaultFailureConverterFailureError(t *testing.T) {
 	require.Equal(t, sourceErr, convErr)
 }
 
+func TestFailureConversionHelpers(t *testing.T) {
+	f := ErrorToFailure(errors.New("test"))
+	require.Equal(t, Failure{Message: "test"}, f)
+	require.Equal(t, &FailureError{Failure: f}, FailureToError(f))
+	require.Equal(t, Failure{}, ErrorToFailure(nil))
+}
+
 type customFailureConverter struct{}
 
 var errCustom = errors.New("custom")
@@ -3,23 +3,29 @@ package nexus
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"maps"
+	"mime"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 // An HandlerStartOperationResult is the return type from the [Handler] StartOperation and [Operation] Start methods. It
-// has two implementations: [HandlerStartOperationResultSync] and [HandlerStartOperationResultAsync].
+// has three implementations: [HandlerStartOperationResultSync], [HandlerStartOperationResultAsync], and
+// [HandlerStartOperationResultDryRun].
 type HandlerStartOperationResult[T any] interface {
-	applyToHTTPResponse(http.ResponseWriter, *httpHandler)
+	applyToHTTPResponse(http.ResponseWriter, *http.Request, *httpHandler, OperationOptions)
 }
 
 // HandlerStartOperationResultSync indicates that an operation completed successfully.
@@ -30,7 +36,26 @@ type HandlerStartOperationResultSync[T any] struct {
 	Links []Link
 }
 
-func (r *HandlerStartOperationResultSync[T]) applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler) {
+// ResultValue implements SyncStartOperationResult.
+func (r *HandlerStartOperationResultSync[T]) ResultValue() any {
+	return r.Value
+}
+
+// ResultLinks implements SyncStartOperationResult.
+func (r *HandlerStartOperationResultSync[T]) ResultLinks() []Link {
+	return r.Links
+}
+
+// SyncStartOperationResult is implemented by [HandlerStartOperationResultSync] of any type parameter, giving access to
+// the value and links of a sync result held as a [HandlerStartOperationResult], e.g. in middleware or tests.
+type SyncStartOperationResult interface {
+	// ResultValue returns the output of the operation.
+	ResultValue() any
+	// ResultLinks returns the links associated with the operation.
+	ResultLinks() []Link
+}
+
+func (r *HandlerStartOperationResultSync[T]) applyToHTTPResponse(writer http.ResponseWriter, request *http.Request, handler *httpHandler, options OperationOptions) {
 	if err := addLinksToHTTPHeader(r.Links, writer.Header()); err != nil {
 		handler.logger.Error("failed to serialize links into header", "error", err)
 		// clear any previous links already written to the header
@@ -39,7 +64,7 @@ func (r *HandlerStartOperationResultSync[T]) applyToHTTPResponse(writer http.Res
 		return
 	}
 
-	handler.writeResult(writer, r.Value)
+	handler.writeResult(writer, request, r.Value, options.ProduceContentTypes, nil)
 }
 
 // HandlerStartOperationResultAsync indicates that an operation has been accepted and will complete asynchronously.
@@ -50,7 +75,7 @@ type HandlerStartOperationResultAsync struct {
 	Links []Link
 }
 
-func (r *HandlerStartOperationResultAsync) applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler) {
+func (r *HandlerStartOperationResultAsync) applyToHTTPResponse(writer http.ResponseWriter, request *http.Request, handler *httpHandler, options OperationOptions) {
 	info := OperationInfo{
 		ID:    r.OperationID,
 		State: OperationStateRunning,
@@ -78,6 +103,15 @@ func (r *HandlerStartOperationResultAsync) applyToHTTPResponse(writer http.Respo
 	}
 }
 
+// HandlerStartOperationResultDryRun indicates that a dry run start request, see [StartOperationOptions.DryRun], passed
+// validation. Responds with a 204 status and no content.
+type HandlerStartOperationResultDryRun struct{}
+
+func (r *HandlerStartOperationResultDryRun) applyToHTTPResponse(writer http.ResponseWriter, request *http.Request, handler *httpHandler, options OperationOptions) {
+	writer.Header().Set(headerDryRun, "true")
+	writer.WriteHeader(http.StatusNoContent)
+}
+
 // A Handler must implement all of the Nexus service endpoints as defined in the [Nexus HTTP API].
 //
 // Handler implementations must embed the [UnimplementedHandler].
@@ -91,7 +125,9 @@ type Handler interface {
 	// StartOperation handles requests for starting an operation. Return [HandlerStartOperationResultSync] to
 	// respond successfully - inline, or [HandlerStartOperationResultAsync] to indicate that an asynchronous
 	// operation was started. Return an [UnsuccessfulOperationError] to indicate that an operation completed as
-	// failed or canceled.
+	// failed or canceled. Return an [OperationRejectedError] to indicate that the operation was not started.
+	// Return [HandlerStartOperationResultDryRun] to respond to a valid [StartOperationOptions.DryRun] request,
+	// dry run requests are only dispatched to handlers that implement [DryRunHandler].
 	StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error)
 	// GetOperationResult handles requests to get the result of an asynchronous operation. Return non error result
 	// to respond successfully - inline, or error with [ErrOperationStillRunning] to indicate that an asynchronous
@@ -113,10 +149,38 @@ type Handler interface {
 	//  1. asynchronous - returning from this method only ensures that cancelation is delivered, it may later be
 	//  ignored by the underlying operation implemention.
 	//  2. idempotent - implementors should ignore duplicate cancelations for the same operation.
-	CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) error
+	//
+	// Implementors may return a nil result if there are no details to report back to the caller.
+	CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) (*CancelOperationResult, error)
+	// RegisterOperationCallback handles requests to register an additional callback for an asynchronous operation
+	// after it was started. This allows callers that could not provide a callback URL at start time to receive push
+	// notifications of the operation's completion.
+	//
+	// Implementations should store the callback alongside any callback provided at start time and deliver the
+	// operation's completion to all registered callbacks. Registration should be idempotent for a given URL.
+	// Implementations should bound the number of callbacks registered per operation and return a [HandlerError] of
+	// type [HandlerErrorTypeResourceExhausted] when that limit is exceeded.
+	RegisterOperationCallback(ctx context.Context, service, operation, operationID string, options RegisterOperationCallbackOptions) error
+	// Close releases resources held by the handler. It should be called once the handler no longer serves requests,
+	// e.g. after shutting down the HTTP server.
+	Close(ctx context.Context) error
 	mustEmbedUnimplementedHandler()
 }
 
+// DryRunHandler may be implemented by a [Handler] to support dry run start requests, see
+// [StartOperationOptions.DryRun]. Dry run requests fail with [HandlerErrorTypeNotImplemented] without calling
+// StartOperation if the handler doesn't implement this interface or SupportsDryRun returns false for the operation.
+//
+// Otherwise, dry run requests are dispatched to StartOperation with the DryRun option set, which must then validate the
+// request without side effects. Whether a handler honored the option can only be verified once StartOperation
+// returned, handlers that return a result other than [HandlerStartOperationResultDryRun] fail the request with an
+// internal error, but any side effects have already happened.
+//
+// Handlers created with [ServiceRegistry.NewHandler] support dry runs for operations that implement [InputValidator].
+type DryRunHandler interface {
+	SupportsDryRun(service, operation string) bool
+}
+
 type HandlerErrorType string
 
 const (
@@ -141,6 +205,35 @@ const (
 	HandlerErrorTypeUpstreamTimeout HandlerErrorType = "UPSTREAM_TIMEOUT"
 )
 
+// DefaultStatusCodeFromHandlerErrorType maps a [HandlerErrorType] to the HTTP status code of the response that carries
+// it, as defined by the Nexus HTTP protocol. Returns false for unknown types.
+//
+// See HandlerOptions.StatusCodeFromHandlerErrorType for overriding the mapping.
+func DefaultStatusCodeFromHandlerErrorType(errorType HandlerErrorType) (int, bool) {
+	switch errorType {
+	case HandlerErrorTypeBadRequest:
+		return http.StatusBadRequest, true
+	case HandlerErrorTypeUnauthenticated:
+		return http.StatusUnauthorized, true
+	case HandlerErrorTypeUnauthorized:
+		return http.StatusForbidden, true
+	case HandlerErrorTypeNotFound:
+		return http.StatusNotFound, true
+	case HandlerErrorTypeResourceExhausted:
+		return http.StatusTooManyRequests, true
+	case HandlerErrorTypeInternal:
+		return http.StatusInternalServerError, true
+	case HandlerErrorTypeNotImplemented:
+		return http.StatusNotImplemented, true
+	case HandlerErrorTypeUnavailable:
+		return http.StatusServiceUnavailable, true
+	case HandlerErrorTypeUpstreamTimeout:
+		return StatusUpstreamTimeout, true
+	default:
+		return 0, false
+	}
+}
+
 // HandlerError is a special error that can be returned from [Handler] methods for failing a request with a custom
 // status code and failure message.
 type HandlerError struct {
@@ -148,6 +241,35 @@ type HandlerError struct {
 	Type HandlerErrorType
 	// The underlying cause for this error.
 	Cause error
+	// Optional quota information for [HandlerErrorTypeResourceExhausted] errors, transmitted to the client as response
+	// headers. Ignored for other error types.
+	Quota *Quota
+	// Optional recommendation on retrying the failed request, e.g. a start request that failed with
+	// [HandlerErrorTypeUnavailable]. Transmitted to the client in the failure's metadata.
+	RetryGuidance *RetryGuidance
+}
+
+// RetryGuidance is a handler's recommendation on whether and how a client should retry a failed request.
+//
+// The SDK does not retry requests on its own. Guidance is advisory: callers and interceptors that implement retries
+// should honor it within the bounds of their own configuration, e.g. never retrying more often than configured locally
+// and waiting at least RetryAfter before the next attempt.
+type RetryGuidance struct {
+	// Minimum time to wait before retrying.
+	RetryAfter time.Duration
+	// Maximum number of attempts the handler recommends, including the failed one. Zero means no recommendation.
+	MaxAttempts int
+	// Maximum random delay to add to RetryAfter to spread retries from multiple clients. Zero means no recommendation.
+	Jitter time.Duration
+}
+
+// Quota describes the state of a quota that has been exhausted, allowing client-side schedulers to decide when to
+// retry.
+type Quota struct {
+	// Number of remaining requests allowed in the current quota window.
+	Remaining int
+	// The time at which the quota resets. Optional.
+	ResetAt time.Time
 }
 
 // HandlerErrorf creates a [HandlerError] with the given type using [fmt.Errorf] to construct the cause.
@@ -178,31 +300,107 @@ func (e *HandlerError) Unwrap() error {
 type baseHTTPHandler struct {
 	logger           *slog.Logger
 	failureConverter FailureConverter
+	// Maps handler error types to response status codes. Defaults to DefaultStatusCodeFromHandlerErrorType if nil.
+	statusCodeFromHandlerErrorType func(HandlerErrorType) (int, bool)
 }
 
 type httpHandler struct {
 	baseHTTPHandler
 	options HandlerOptions
+	// Total size of start request bodies currently being handled, see HandlerOptions.MaxInFlightRequestBytes.
+	inFlightRequestBytes atomic.Int64
 }
 
-func (h *httpHandler) writeResult(writer http.ResponseWriter, result any) {
+var errInFlightRequestBytesExceeded = HandlerErrorf(HandlerErrorTypeResourceExhausted, "in-flight request bytes limit exceeded")
+
+// reserveInFlightRequestBytes reserves n bytes against the MaxInFlightRequestBytes limit. Returns false if the limit
+// would be exceeded.
+func (h *httpHandler) reserveInFlightRequestBytes(n int64) bool {
+	for {
+		current := h.inFlightRequestBytes.Load()
+		if current+n > h.options.MaxInFlightRequestBytes {
+			return false
+		}
+		if h.inFlightRequestBytes.CompareAndSwap(current, current+n) {
+			return true
+		}
+	}
+}
+
+// inputSinkReader reads a start request body through the reader returned by HandlerOptions.InputSink.
+type inputSinkReader struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+func (r *inputSinkReader) Close() error {
+	if c, ok := r.Reader.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			_ = r.body.Close()
+			return err
+		}
+	}
+	return r.body.Close()
+}
+
+// inFlightBytesReader reserves bytes of a request body of unknown length against the MaxInFlightRequestBytes limit as
+// they are read, failing the read once the limit would be exceeded.
+type inFlightBytesReader struct {
+	io.ReadCloser
+	handler  *httpHandler
+	reserved int64
+}
+
+func (r *inFlightBytesReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if !r.handler.reserveInFlightRequestBytes(int64(n)) {
+			return 0, errInFlightRequestBytesExceeded
+		}
+		r.reserved += int64(n)
+	}
+	return n, err
+}
+
+// writeResult writes a handler result to the response, along with metadata to transmit as response headers, see
+// Content.Metadata. Results are serialized with the handler's serializer, negotiated from the request's Accept header
+// if it is a [NegotiatingSerializer].
+//
+// Results provided as a [*Content] or [*Reader] bypass the handler's serializer and are written as is, with the content
+// type and other content headers taken from their Header. Handlers can use this to force a specific format for a result.
+//
+// If produceContentTypes is not empty, results with a content type not in the list are replaced with an internal error.
+func (h *httpHandler) writeResult(writer http.ResponseWriter, request *http.Request, result any, produceContentTypes []string, metadata Header) {
+	serializer := negotiateSerializer(h.options.Serializer, request.Header)
 	var reader *Reader
+	_, isContent := result.(*Content)
 	if r, ok := result.(*Reader); ok {
 		// Close the request body in case we error before sending the HTTP request (which may double close but
 		// that's fine since we ignore the error).
 		defer r.Close()
 		reader = r
+	} else if s, ok := serializer.(StreamSerializer); ok && !isContent {
+		body, header, err := s.SerializeStream(result)
+		if err != nil {
+			h.writeFailure(writer, fmt.Errorf("failed to serialize handler result: %w", err))
+			return
+		}
+		defer body.Close()
+		reader = &Reader{body, header}
 	} else {
 		content, ok := result.(*Content)
 		if !ok {
 			var err error
-			content, err = h.options.Serializer.Serialize(result)
+			content, err = serializer.Serialize(result)
 			if err != nil {
 				h.writeFailure(writer, fmt.Errorf("failed to serialize handler result: %w", err))
 				return
 			}
 		}
 		header := maps.Clone(content.Header)
+		if header == nil {
+			header = make(Header, 1)
+		}
 		header["length"] = strconv.Itoa(len(content.Data))
 
 		reader = &Reader{
@@ -211,7 +409,14 @@ func (h *httpHandler) writeResult(writer http.ResponseWriter, result any) {
 		}
 	}
 
+	if contentType := reader.Header.Get("type"); !contentTypeAllowed(contentType, produceContentTypes) {
+		h.logger.Error("operation produced a result with a disallowed content type", "type", contentType)
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeInternal, "internal error"))
+		return
+	}
+
 	header := writer.Header()
+	addResultMetadataToHTTPHeader(metadata, header)
 	addContentHeaderToHTTPHeader(reader.Header, header)
 	if reader.ReadCloser == nil {
 		return
@@ -225,16 +430,23 @@ func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
 	var failure Failure
 	var unsuccessfulError *UnsuccessfulOperationError
 	var handlerError *HandlerError
+	var rejectedError *OperationRejectedError
 	var operationState OperationState
 	statusCode := http.StatusInternalServerError
 
-	if errors.As(err, &unsuccessfulError) {
+	if errors.As(err, &rejectedError) {
+		failure = h.failureConverter.ErrorToFailure(rejectedError.Cause)
+		statusCode = statusOperationRejected
+	} else if errors.As(err, &unsuccessfulError) {
 		operationState = unsuccessfulError.State
 		failure = h.failureConverter.ErrorToFailure(unsuccessfulError.Cause)
 		statusCode = statusOperationFailed
 
 		if operationState == OperationStateFailed || operationState == OperationStateCanceled {
 			writer.Header().Set(headerOperationState, string(operationState))
+			if unsuccessfulError.Retryable != nil {
+				writer.Header().Set(headerOperationRetryable, strconv.FormatBool(*unsuccessfulError.Retryable))
+			}
 		} else {
 			h.logger.Error("unexpected operation state", "state", operationState)
 			writer.WriteHeader(http.StatusInternalServerError)
@@ -242,26 +454,19 @@ func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
 		}
 	} else if errors.As(err, &handlerError) {
 		failure = h.failureConverter.ErrorToFailure(handlerError.Cause)
-		switch handlerError.Type {
-		case HandlerErrorTypeBadRequest:
-			statusCode = http.StatusBadRequest
-		case HandlerErrorTypeUnauthenticated:
-			statusCode = http.StatusUnauthorized
-		case HandlerErrorTypeUnauthorized:
-			statusCode = http.StatusForbidden
-		case HandlerErrorTypeNotFound:
-			statusCode = http.StatusNotFound
-		case HandlerErrorTypeResourceExhausted:
-			statusCode = http.StatusTooManyRequests
-		case HandlerErrorTypeInternal:
-			statusCode = http.StatusInternalServerError
-		case HandlerErrorTypeNotImplemented:
-			statusCode = http.StatusNotImplemented
-		case HandlerErrorTypeUnavailable:
-			statusCode = http.StatusServiceUnavailable
-		case HandlerErrorTypeUpstreamTimeout:
-			statusCode = StatusUpstreamTimeout
-		default:
+		if handlerError.Type == HandlerErrorTypeResourceExhausted && handlerError.Quota != nil {
+			addQuotaToHTTPHeader(handlerError.Quota, writer.Header())
+		}
+		if handlerError.RetryGuidance != nil {
+			failure.Metadata = addRetryGuidanceToFailureMetadata(handlerError.RetryGuidance, failure.Metadata)
+		}
+		statusCodeFromHandlerErrorType := h.statusCodeFromHandlerErrorType
+		if statusCodeFromHandlerErrorType == nil {
+			statusCodeFromHandlerErrorType = DefaultStatusCodeFromHandlerErrorType
+		}
+		if code, ok := statusCodeFromHandlerErrorType(handlerError.Type); ok {
+			statusCode = code
+		} else {
 			h.logger.Error("unexpected handler error type", "type", handlerError.Type)
 		}
 	} else {
@@ -292,17 +497,63 @@ func (h *httpHandler) startOperation(service, operation string, writer http.Resp
 		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid %q header", headerLink))
 		return
 	}
+	var dryRun bool
+	if dryRunStr := request.Header.Get(headerDryRun); dryRunStr != "" {
+		if dryRun, err = strconv.ParseBool(dryRunStr); err != nil {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid %q header", headerDryRun))
+			return
+		}
+	}
 	options := StartOperationOptions{
 		RequestID:      request.Header.Get(headerRequestID),
 		CallbackURL:    request.URL.Query().Get(queryCallbackURL),
 		CallbackHeader: prefixStrippedHTTPHeaderToNexusHeader(request.Header, "nexus-callback-"),
-		Header:         httpHeaderToNexusHeader(request.Header, "content-", "nexus-callback-"),
+		Header:         httpHeaderToNexusHeader(request.Header, "content-", "nexus-callback-", headerDryRun),
 		Links:          links,
+		DryRun:         dryRun,
+	}
+	if dryRun {
+		if d, ok := h.options.Handler.(DryRunHandler); !ok || !d.SupportsDryRun(service, operation) {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeNotImplemented, "operation %q does not support dry run", operation))
+			return
+		}
+	}
+	operationOptions := h.operationOptions(service, operation)
+	if contentType := request.Header.Get("Content-Type"); !contentTypeAllowed(contentType, operationOptions.AcceptContentTypes) {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "unsupported input content type: %q", contentType))
+		return
+	}
+	body := request.Body
+	if h.options.MaxRequestBodySize > 0 {
+		if request.ContentLength > h.options.MaxRequestBodySize {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "request body exceeds max size of %d bytes", h.options.MaxRequestBodySize))
+			return
+		}
+		body = http.MaxBytesReader(writer, body, h.options.MaxRequestBodySize)
+	}
+	if h.options.MaxInFlightRequestBytes > 0 {
+		// Bytes are released once the handler returns since the input must not be used after that.
+		if request.ContentLength > h.options.MaxInFlightRequestBytes {
+			// Retrying can't help a request that exceeds the limit on its own.
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "request body exceeds max in flight size of %d bytes", h.options.MaxInFlightRequestBytes))
+			return
+		}
+		if request.ContentLength >= 0 {
+			if !h.reserveInFlightRequestBytes(request.ContentLength) {
+				h.writeFailure(writer, errInFlightRequestBytesExceeded)
+				return
+			}
+			defer h.inFlightRequestBytes.Add(-request.ContentLength)
+		} else {
+			reader := &inFlightBytesReader{ReadCloser: body, handler: h}
+			defer func() { h.inFlightRequestBytes.Add(-reader.reserved) }()
+			body = reader
+		}
 	}
 	value := &LazyValue{
 		serializer: h.options.Serializer,
 		Reader: &Reader{
-			request.Body,
+			body,
 			prefixStrippedHTTPHeaderToNexusHeader(request.Header, "content-"),
 		},
 	}
@@ -313,19 +564,52 @@ func (h *httpHandler) startOperation(service, operation string, writer http.Resp
 	}
 	defer cancel()
 
+	if h.options.InputSink != nil {
+		info := HandlerInfo{Service: service, Operation: operation, Header: options.Header}
+		value.Reader.ReadCloser = &inputSinkReader{h.options.InputSink(ctx, info, body), body}
+	}
+
 	response, err := h.options.Handler.StartOperation(ctx, service, operation, value, options)
 	if err != nil {
 		h.writeFailure(writer, err)
-	} else {
-		response.applyToHTTPResponse(writer, h)
+		return
 	}
+	if _, ok := response.(*HandlerStartOperationResultDryRun); ok != dryRun {
+		h.logger.Error("handler did not honor dry run option", "service", service, "operation", operation, "dryRun", dryRun)
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeInternal, "internal server error"))
+		return
+	}
+	if async, ok := response.(*HandlerStartOperationResultAsync); ok {
+		if h.options.MaxOperationIDLength > 0 && len(async.OperationID) > h.options.MaxOperationIDLength {
+			h.logger.Error("operation ID exceeds max length", "service", service, "operation", operation, "length", len(async.OperationID), "max", h.options.MaxOperationIDLength)
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeInternal, "internal error"))
+			return
+		}
+		if h.options.EmitLocationHeader {
+			writer.Header().Set("Location", operationLocation(request.URL.EscapedPath(), async.OperationID))
+		}
+	}
+	response.applyToHTTPResponse(writer, request, h, operationOptions)
+}
+
+// operationLocation returns a reference to the resource of an operation started by a request with the given escaped
+// path, i.e. the request path without trailing slashes followed by the operation ID. The reference is relative to the
+// request URL to remain correct when the handler is mounted under a path prefix.
+func operationLocation(requestPath, operationID string) string {
+	trimmed := strings.TrimRight(requestPath, "/")
+	id := url.PathEscape(operationID)
+	if slashes := len(requestPath) - len(trimmed); slashes > 0 {
+		// The request URL already resolves relative references under the operation path.
+		return strings.Repeat("../", slashes-1) + "./" + id
+	}
+	return "./" + trimmed[strings.LastIndex(trimmed, "/")+1:] + "/" + id
 }
 
 func (h *httpHandler) getOperationResult(service, operation, operationID string, writer http.ResponseWriter, request *http.Request) {
 	options := GetOperationResultOptions{Header: httpHeaderToNexusHeader(request.Header)}
 
 	// If both Request-Timeout http header and wait query string are set, the minimum of the Request-Timeout header
-	// and h.options.GetResultTimeout will be used.
+	// and the operation's get result timeout will be used.
 	ctx := request.Context()
 	requestTimeout, ok := h.parseRequestTimeoutHeader(writer, request)
 	if !ok {
@@ -340,15 +624,17 @@ func (h *httpHandler) getOperationResult(service, operation, operationID string,
 			return
 		}
 		options.Wait = waitDuration
+		getResultTimeout := h.getResultTimeout(service, operation)
 		if requestTimeout > 0 {
-			requestTimeout = min(requestTimeout, h.options.GetResultTimeout)
+			requestTimeout = min(requestTimeout, getResultTimeout)
 		} else {
-			requestTimeout = h.options.GetResultTimeout
+			requestTimeout = getResultTimeout
 		}
+		writer.Header().Set(headerMaxWait, formatDuration(requestTimeout))
 	}
 	if requestTimeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(request.Context(), requestTimeout)
+		ctx, cancel = contextWithRequestTimeout(request.Context(), requestTimeout)
 		defer cancel()
 	}
 
@@ -363,11 +649,18 @@ func (h *httpHandler) getOperationResult(service, operation, operationID string,
 		}
 		return
 	}
-	h.writeResult(writer, result)
+	var metadata Header
+	if content, ok := result.(*Content); ok && content != nil {
+		metadata = content.Metadata
+	}
+	h.writeResult(writer, request, result, h.operationOptions(service, operation).ProduceContentTypes, metadata)
 }
 
 func (h *httpHandler) getOperationInfo(service, operation, operationID string, writer http.ResponseWriter, request *http.Request) {
-	options := GetOperationInfoOptions{Header: httpHeaderToNexusHeader(request.Header)}
+	options := GetOperationInfoOptions{
+		Header:      httpHeaderToNexusHeader(request.Header),
+		IfNoneMatch: request.Header.Get("If-None-Match"),
+	}
 
 	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
 	if !ok {
@@ -380,6 +673,19 @@ func (h *httpHandler) getOperationInfo(service, operation, operationID string, w
 		h.writeFailure(writer, err)
 		return
 	}
+	if info == nil {
+		h.logger.Error("handler returned no operation info", "service", service, "operation", operation)
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeInternal, "internal error"))
+		return
+	}
+	if info.ETag != "" {
+		etag := quoteETag(info.ETag)
+		writer.Header().Set("ETag", etag)
+		if options.IfNoneMatch != "" && etagMatches(options.IfNoneMatch, etag) {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
 
 	bytes, err := json.Marshal(info)
 	if err != nil {
@@ -392,6 +698,59 @@ func (h *httpHandler) getOperationInfo(service, operation, operationID string, w
 	}
 }
 
+// quoteETag returns etag as an entity tag, quoting it unless it already is a strong or weak entity tag, e.g. "v1" or
+// W/"v1".
+func quoteETag(etag string) string {
+	if tag, remain := scanETag(etag); tag != "" && remain == "" {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// etagMatches reports whether an If-None-Match header value matches the given entity tag: either "*" or a comma
+// separated list of entity tags, compared using the weak comparison of RFC 9110 section 8.8.3.2.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for {
+		ifNoneMatch = strings.TrimLeft(ifNoneMatch, " \t")
+		if ifNoneMatch == "" {
+			return false
+		}
+		if ifNoneMatch[0] == ',' {
+			ifNoneMatch = ifNoneMatch[1:]
+			continue
+		}
+		if ifNoneMatch[0] == '*' {
+			return true
+		}
+		tag, remain := scanETag(ifNoneMatch)
+		if tag == "" {
+			return false
+		}
+		if strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+		ifNoneMatch = remain
+	}
+}
+
+// scanETag returns the entity tag at the start of s and the rest of s, or an empty tag if s doesn't start with one.
+func scanETag(s string) (tag string, remain string) {
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s) <= start || s[start] != '"' {
+		return "", ""
+	}
+	end := strings.IndexByte(s[start+1:], '"')
+	if end < 0 {
+		return "", ""
+	}
+	end += start + 2
+	return s[:end], s[end:]
+}
+
 func (h *httpHandler) cancelOperation(service, operation, operationID string, writer http.ResponseWriter, request *http.Request) {
 	options := CancelOperationOptions{Header: httpHeaderToNexusHeader(request.Header)}
 
@@ -401,29 +760,171 @@ func (h *httpHandler) cancelOperation(service, operation, operationID string, wr
 	}
 	defer cancel()
 
-	if err := h.options.Handler.CancelOperation(ctx, service, operation, operationID, options); err != nil {
+	result, err := h.options.Handler.CancelOperation(ctx, service, operation, operationID, options)
+	if err != nil {
 		h.writeFailure(writer, err)
 		return
 	}
+	if result != nil {
+		if err := addLinksToHTTPHeader(result.Links, writer.Header()); err != nil {
+			h.logger.Error("failed to serialize links into header", "error", err)
+			// clear any previous links already written to the header
+			writer.Header().Del(headerLink)
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		addCancelMetadataToHTTPHeader(result.Metadata, writer.Header())
+	}
 
 	writer.WriteHeader(http.StatusAccepted)
 }
 
+func (h *httpHandler) registerOperationCallback(service, operation, operationID string, writer http.ResponseWriter, request *http.Request) {
+	options := RegisterOperationCallbackOptions{
+		CallbackURL:    request.URL.Query().Get(queryCallbackURL),
+		CallbackHeader: prefixStrippedHTTPHeaderToNexusHeader(request.Header, "nexus-callback-"),
+		Header:         httpHeaderToNexusHeader(request.Header, "nexus-callback-"),
+	}
+	if options.CallbackURL == "" {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "missing %q query parameter", queryCallbackURL))
+		return
+	}
+
+	ctx, cancel, ok := h.contextWithTimeoutFromHTTPRequest(writer, request)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	if err := h.options.Handler.RegisterOperationCallback(ctx, service, operation, operationID, options); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// getResultTimeout returns the max duration to allow waiting for a single get result request for the given operation.
+// Handlers that provide per-operation options (i.e. handlers created by [ServiceRegistry.NewHandler]) may override
+// HandlerOptions.GetResultTimeout.
+func (h *httpHandler) getResultTimeout(service, operation string) time.Duration {
+	if options := h.operationOptions(service, operation); options.GetResultTimeout > 0 {
+		return options.GetResultTimeout
+	}
+	return h.options.GetResultTimeout
+}
+
+// operationOptions returns the options the given operation was registered with, for handlers that provide
+// per-operation options (i.e. handlers created by [ServiceRegistry.NewHandler]).
+func (h *httpHandler) operationOptions(service, operation string) OperationOptions {
+	if p, ok := h.options.Handler.(operationOptionsProvider); ok {
+		if options, ok := p.operationOptions(service, operation); ok {
+			return options
+		}
+	}
+	return OperationOptions{}
+}
+
+const serviceDiscoveryPath = "/_services"
+
+// ServiceDescription describes a registered service in the document served when
+// [HandlerOptions.EnableServiceDiscovery] is set.
+type ServiceDescription struct {
+	Name string `json:"name"`
+	// Operations of the service, sorted by name.
+	Operations []OperationDescription `json:"operations"`
+}
+
+// OperationDescription describes a registered operation in the document served when
+// [HandlerOptions.EnableServiceDiscovery] is set.
+type OperationDescription struct {
+	Name string `json:"name"`
+	// Go type of the operation's input, e.g. "string" or "mypackage.MyInput". Empty if the operation does not expose
+	// its types via InputType and OutputType methods, as [Operation] implementations do.
+	InputType string `json:"inputType,omitempty"`
+	// Go type of the operation's output. Empty if the operation does not expose its types.
+	OutputType string `json:"outputType,omitempty"`
+}
+
+// serviceDescriber is implemented by handlers that support service discovery.
+type serviceDescriber interface {
+	describeServices() []ServiceDescription
+}
+
+func (h *httpHandler) describeServices(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != "GET" {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request method: expected GET, got %q", request.Method))
+		return
+	}
+	d, ok := h.options.Handler.(serviceDescriber)
+	if !ok {
+		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeNotImplemented, "service discovery not supported by handler"))
+		return
+	}
+	bytes, err := json.Marshal(struct {
+		Services []ServiceDescription `json:"services"`
+	}{d.describeServices()})
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal service descriptions: %w", err))
+		return
+	}
+	writer.Header().Set("Content-Type", contentTypeJSON)
+	if _, err := writer.Write(bytes); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// contentTypeAllowed reports whether the media type of contentType is in allowed. Empty content types, i.e. empty
+// values, and empty allow lists allow any content type.
+func contentTypeAllowed(contentType string, allowed []string) bool {
+	if contentType == "" || len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, a := range allowed {
+		if allowedMediaType, _, err := mime.ParseMediaType(a); err == nil && allowedMediaType == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
 // parseRequestTimeoutHeader checks if the Request-Timeout HTTP header is set and returns the parsed duration if so.
 // Returns (0, true) if unset. Returns ({parsedDuration}, true) if set. If set and there is an error parsing the
 // duration, it writes a failure response and returns (0, false).
+//
+// If the Nexus-Deadline header is set, the remaining time until the absolute deadline, extended by
+// HandlerOptions.DeadlineClockSkewTolerance, is used instead when it is shorter than the relative timeout.
 func (h *httpHandler) parseRequestTimeoutHeader(writer http.ResponseWriter, request *http.Request) (time.Duration, bool) {
+	var timeout time.Duration
 	timeoutStr := request.Header.Get(HeaderRequestTimeout)
 	if timeoutStr != "" {
-		timeoutDuration, err := parseDuration(timeoutStr)
+		timeoutDuration, err := ParseRequestTimeout(timeoutStr)
 		if err != nil {
 			h.logger.Warn("invalid request timeout header", "timeout", timeoutStr)
 			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request timeout header"))
 			return 0, false
 		}
-		return timeoutDuration, true
+		timeout = timeoutDuration
+	}
+	deadlineStr := request.Header.Get(headerDeadline)
+	if deadlineStr != "" {
+		deadline, err := time.Parse(time.RFC3339Nano, deadlineStr)
+		if err != nil {
+			h.logger.Warn("invalid deadline header", "deadline", deadlineStr)
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid deadline header"))
+			return 0, false
+		}
+		// Ensure an expired deadline results in an expired context rather than no timeout at all.
+		remaining := max(time.Until(deadline)+h.options.DeadlineClockSkewTolerance, time.Nanosecond)
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
 	}
-	return 0, true
+	return timeout, true
 }
 
 // contextWithTimeoutFromHTTPRequest extracts the context from the HTTP request and applies the timeout indicated by
@@ -434,12 +935,32 @@ func (h *httpHandler) contextWithTimeoutFromHTTPRequest(writer http.ResponseWrit
 		return nil, nil, false
 	}
 	if requestTimeout > 0 {
-		ctx, cancel := context.WithTimeout(request.Context(), requestTimeout)
+		ctx, cancel := contextWithRequestTimeout(request.Context(), requestTimeout)
 		return ctx, cancel, true
 	}
 	return request.Context(), func() {}, true
 }
 
+type requestTimeoutKeyType struct{}
+
+var requestTimeoutKey = requestTimeoutKeyType{}
+
+// contextWithRequestTimeout applies the given timeout to ctx and records it for [ExtractRequestTimeout].
+func contextWithRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithValue(ctx, requestTimeoutKey, timeout), timeout)
+}
+
+// ExtractRequestTimeout returns the timeout the handler applied to the request being handled, derived from the
+// Request-Timeout and Nexus-Deadline headers and, for get result requests that wait, the get result timeout. Returns
+// false if no timeout was applied. Available in [Handler] methods, [MiddlewareFunc]s, and [Operation] methods invoked
+// via a handler created with [NewHTTPHandler].
+//
+// This is intended for diagnostics, use the context deadline to bound work.
+func ExtractRequestTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(requestTimeoutKey).(time.Duration)
+	return timeout, ok
+}
+
 // HandlerOptions are options for [NewHTTPHandler].
 type HandlerOptions struct {
 	// Handler for handling service requests.
@@ -449,6 +970,7 @@ type HandlerOptions struct {
 	Logger *slog.Logger
 	// Max duration to allow waiting for a single get result request.
 	// Enforced if provided for requests with the wait query parameter set.
+	// May be overridden per operation via [OperationOptions.GetResultTimeout].
 	//
 	// Defaults to one minute.
 	GetResultTimeout time.Duration
@@ -458,10 +980,168 @@ type HandlerOptions struct {
 	// A [FailureConverter] to convert a [Failure] instance to and from an [error].
 	// Defaults to [DefaultFailureConverter].
 	FailureConverter FailureConverter
+	// If true, responses to start requests that result in an asynchronous operation include a Location header
+	// identifying the created operation resource ({service}/{operation}/{operation_id}).
+	// The header value is a URL reference relative to the start request URL.
+	EmitLocationHeader bool
+	// If true, requests carrying "Nexus-" prefixed headers that are not part of the Nexus protocol, nor listed in
+	// AllowedNexusHeaders, are rejected with a [HandlerErrorTypeBadRequest] error. Headers without the "Nexus-"
+	// prefix are unaffected.
+	StrictHeaders bool
+	// Additional "Nexus-" prefixed headers to allow when StrictHeaders is set. Keys are case-insensitive.
+	AllowedNexusHeaders []string
+	// Max length of operation IDs, which flow through URLs and headers and may break proxies when overly long.
+	// Operation IDs returned by asynchronous operations that exceed this length fail the start request with an
+	// internal error. Requests for operations with IDs that exceed this length are rejected as bad requests.
+	//
+	// Defaults to 4096. Set to a negative value to disable the validation.
+	MaxOperationIDLength int
+	// Maximum total size in bytes of start operation request bodies handled concurrently, to bound the memory used by
+	// operation inputs. Start requests that would exceed the limit are rejected with a
+	// [HandlerErrorTypeResourceExhausted] error, and requests that declare a body larger than the limit, which can never
+	// be handled, with a non-retryable [HandlerErrorTypeBadRequest] error.
+	//
+	// Bodies of known length are accounted for upfront. Bodies of unknown length (e.g. chunked requests) are accounted
+	// for as they are read, failing the read once the limit would be exceeded. Bytes are released when the handler
+	// method returns.
+	// Unlimited by default.
+	MaxInFlightRequestBytes int64
+	// Maximum size in bytes of a start operation request body. Requests that declare a larger body are rejected with a
+	// [HandlerErrorTypeBadRequest] error. Otherwise, reading the input fails once the limit is exceeded, which
+	// operations registered with a [ServiceRegistry] report as a [HandlerErrorTypeBadRequest] error.
+	// Unlimited by default.
+	MaxRequestBodySize int64
+	// Tolerance for clock skew between callers and the handler, applied when computing the remaining time budget of a
+	// request from an absolute deadline, see [HTTPClientOptions.SendAbsoluteDeadline]. A positive value extends the
+	// budget to avoid prematurely expiring requests from callers with clocks running ahead.
+	DeadlineClockSkewTolerance time.Duration
+	// An optional function to observe operation inputs, e.g. to archive them for auditing. It is called for every start
+	// request with the request body and returns the reader the operation reads its input from. Use [io.TeeReader] to
+	// copy the input to a sink as the operation reads it, without buffering it in memory. Inputs that the operation
+	// doesn't read in their entirety are only partially copied.
+	//
+	// The body passed to the sink is subject to MaxRequestBodySize and MaxInFlightRequestBytes, i.e. reads fail once
+	// a limit is exceeded. Bodies are passed as sent by the caller; the handler does not decode any Content-Encoding.
+	// The body is closed by the handler. If the returned reader is an [io.Closer], it is closed before the body.
+	InputSink func(ctx context.Context, info HandlerInfo, input io.Reader) io.Reader
+	// An optional function returning a context that bounds the lifetime of all requests, called once per request.
+	// Canceling the returned context cancels the contexts of in-flight requests; only its cancelation propagates,
+	// values are taken from the request context.
+	//
+	// Use this to wind down long polling get result requests when shutting down. [http.Server.Shutdown] waits for
+	// in-flight requests to complete, which may take up to GetResultTimeout for long polls. Cancel the base context
+	// before or while calling Shutdown to have long polls respond immediately with a timeout, which callers treat as a
+	// signal to poll again, possibly against another server.
+	BaseContext func() context.Context
+	// If set, the handler serves a JSON document describing the registered services and operations, including their
+	// input and output Go types, at GET /_services, for building generic clients and dashboards. Only supported for
+	// handlers created by [ServiceRegistry.NewHandler].
+	//
+	// Disabled by default to avoid exposing implementation details.
+	EnableServiceDiscovery bool
+	// If set, trailing slashes in request paths, as appended by some proxies, are ignored when routing requests.
+	TrimTrailingSlash bool
+	// If set, operation names in request paths are matched case-insensitively against the registered operations of a
+	// service, for proxies that alter the case of paths. An exact match takes precedence. Names that match multiple
+	// operations which only differ by case are ambiguous and are not resolved, making those operations unreachable
+	// with a differently cased name. Only supported for handlers created by [ServiceRegistry.NewHandler].
+	CaseInsensitiveOperations bool
+	// An optional function to map handler error types to the HTTP status codes of the responses that carry them, for
+	// gateways with nonstandard status conventions. Unknown types, for which it returns false, are responded to with
+	// status 500. Clients must use a matching HTTPClientOptions.HandlerErrorTypeFromStatusCode mapping.
+	//
+	// Defaults to [DefaultStatusCodeFromHandlerErrorType]. Overrides may fall back to it for types they don't handle.
+	StatusCodeFromHandlerErrorType func(HandlerErrorType) (int, bool)
+}
+
+const defaultMaxOperationIDLength = 4096
+
+// Nexus protocol headers accepted by the handler when HandlerOptions.StrictHeaders is set.
+var knownNexusHeaders = []string{
+	headerRequestID,
+	headerLink,
+	headerOperationState,
+	headerOperationStartTime,
+	HeaderOperationID,
+	headerDeadline,
+	headerDryRun,
+}
+
+// validateNexusHeaders returns an error if the request has a "Nexus-" prefixed header that is neither a known protocol
+// header nor explicitly allowed.
+func (h *httpHandler) validateNexusHeaders(header http.Header) error {
+headerLoop:
+	for k := range header {
+		lowerK := strings.ToLower(k)
+		if !strings.HasPrefix(lowerK, "nexus-") || strings.HasPrefix(lowerK, "nexus-callback-") {
+			continue
+		}
+		if slices.Contains(knownNexusHeaders, lowerK) {
+			continue
+		}
+		for _, allowed := range h.options.AllowedNexusHeaders {
+			if strings.ToLower(allowed) == lowerK {
+				continue headerLoop
+			}
+		}
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "unknown Nexus header: %q", k)
+	}
+	return nil
+}
+
+type tlsConnectionStateKeyType struct{}
+
+var tlsConnectionStateKey = tlsConnectionStateKeyType{}
+
+// ExtractTLSConnectionState returns the TLS connection state of the HTTP request being handled, or nil for requests
+// that were not received over TLS. Available in [Handler] methods, [MiddlewareFunc]s, and [Operation] methods invoked
+// via a handler created with [NewHTTPHandler].
+func ExtractTLSConnectionState(ctx context.Context) *tls.ConnectionState {
+	state, _ := ctx.Value(tlsConnectionStateKey).(*tls.ConnectionState)
+	return state
+}
+
+// ExtractPeerCertificate returns the leaf certificate of the first verified chain presented by the client of the HTTP
+// request being handled. Returns nil for non-TLS requests and requests without a verified client certificate.
+//
+// ⚠️ Certificates are only verified when the server's [tls.Config] is configured to verify client certificates, i.e.
+// ClientAuth is set to [tls.VerifyClientCertIfGiven] or [tls.RequireAndVerifyClientCert]. Unverified certificates, which
+// are available via [ExtractTLSConnectionState], must not be used for authorization.
+func ExtractPeerCertificate(ctx context.Context) *x509.Certificate {
+	state := ExtractTLSConnectionState(ctx)
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
 }
 
 func (h *httpHandler) handleRequest(writer http.ResponseWriter, request *http.Request) {
-	parts := strings.Split(request.URL.EscapedPath(), "/")
+	if h.options.BaseContext != nil {
+		base := h.options.BaseContext()
+		ctx, cancel := context.WithCancelCause(request.Context())
+		defer cancel(nil)
+		stop := context.AfterFunc(base, func() { cancel(context.Cause(base)) })
+		defer stop()
+		request = request.WithContext(ctx)
+	}
+	if request.TLS != nil {
+		request = request.WithContext(context.WithValue(request.Context(), tlsConnectionStateKey, request.TLS))
+	}
+	if h.options.StrictHeaders {
+		if err := h.validateNexusHeaders(request.Header); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
+	}
+	path := request.URL.EscapedPath()
+	if h.options.TrimTrailingSlash {
+		path = strings.TrimRight(path, "/")
+	}
+	if h.options.EnableServiceDiscovery && path == serviceDiscoveryPath {
+		h.describeServices(writer, request)
+		return
+	}
+	parts := strings.Split(path, "/")
 	// First part is empty (due to leading /)
 	if len(parts) < 3 {
 		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeNotFound, "not found"))
@@ -477,6 +1157,11 @@ func (h *httpHandler) handleRequest(writer http.ResponseWriter, request *http.Re
 		h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
 		return
 	}
+	if h.options.CaseInsensitiveOperations {
+		if r, ok := h.options.Handler.(operationNameResolver); ok {
+			operation = r.resolveOperationName(service, operation)
+		}
+	}
 	var operationID string
 	if len(parts) > 3 {
 		operationID, err = url.PathUnescape(parts[3])
@@ -484,6 +1169,10 @@ func (h *httpHandler) handleRequest(writer http.ResponseWriter, request *http.Re
 			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "failed to parse URL path"))
 			return
 		}
+		if h.options.MaxOperationIDLength > 0 && len(operationID) > h.options.MaxOperationIDLength {
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "operation ID exceeds max length of %d", h.options.MaxOperationIDLength))
+			return
+		}
 	}
 
 	switch len(parts) {
@@ -494,11 +1183,16 @@ func (h *httpHandler) handleRequest(writer http.ResponseWriter, request *http.Re
 		}
 		h.startOperation(service, operation, writer, request)
 	case 4: // /{service}/{operation}/{operation_id}
-		if request.Method != "GET" {
-			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request method: expected GET, got %q", request.Method))
-			return
+		switch request.Method {
+		case "GET":
+			h.getOperationInfo(service, operation, operationID, writer, request)
+		case "DELETE":
+			// Equivalent to POST /{service}/{operation}/{operation_id}/cancel, for gateways that map cancelation to
+			// deleting the operation resource.
+			h.cancelOperation(service, operation, operationID, writer, request)
+		default:
+			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request method: expected GET or DELETE, got %q", request.Method))
 		}
-		h.getOperationInfo(service, operation, operationID, writer, request)
 	case 5:
 		switch parts[4] {
 		case "result": // /{service}/{operation}/{operation_id}/result
@@ -513,6 +1207,12 @@ func (h *httpHandler) handleRequest(writer http.ResponseWriter, request *http.Re
 				return
 			}
 			h.cancelOperation(service, operation, operationID, writer, request)
+		case "callbacks": // /{service}/{operation}/{operation_id}/callbacks
+			if request.Method != "POST" {
+				h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid request method: expected POST, got %q", request.Method))
+				return
+			}
+			h.registerOperationCallback(service, operation, operationID, writer, request)
 		default:
 			h.writeFailure(writer, HandlerErrorf(HandlerErrorTypeNotFound, "not found"))
 		}
@@ -529,6 +1229,9 @@ func NewHTTPHandler(options HandlerOptions) http.Handler {
 	if options.GetResultTimeout == 0 {
 		options.GetResultTimeout = time.Minute
 	}
+	if options.MaxOperationIDLength == 0 {
+		options.MaxOperationIDLength = defaultMaxOperationIDLength
+	}
 	if options.Serializer == nil {
 		options.Serializer = defaultSerializer
 	}
@@ -537,11 +1240,35 @@ func NewHTTPHandler(options HandlerOptions) http.Handler {
 	}
 	handler := &httpHandler{
 		baseHTTPHandler: baseHTTPHandler{
-			logger:           options.Logger,
-			failureConverter: options.FailureConverter,
+			logger:                         options.Logger,
+			failureConverter:               options.FailureConverter,
+			statusCodeFromHandlerErrorType: options.StatusCodeFromHandlerErrorType,
 		},
 		options: options,
 	}
 
 	return http.HandlerFunc(handler.handleRequest)
 }
+
+// NewSingleOperationHTTPHandler constructs an [http.Handler] that serves a single operation under the given service
+// name, at /{service}/{operation}. Options are applied as in [NewHTTPHandler], HandlerOptions.Handler is ignored.
+//
+// This is a convenience for prototypes and tests, equivalent to registering the operation on a [Service] and
+// [ServiceRegistry] and creating an HTTP handler from the registry's handler. Use the full registry API to serve
+// multiple operations or to register middleware.
+func NewSingleOperationHTTPHandler(service string, operation RegisterableOperation, options HandlerOptions) (http.Handler, error) {
+	svc := NewService(service)
+	if err := svc.Register(operation); err != nil {
+		return nil, err
+	}
+	registry := NewServiceRegistry()
+	if err := registry.Register(svc); err != nil {
+		return nil, err
+	}
+	handler, err := registry.NewHandler()
+	if err != nil {
+		return nil, err
+	}
+	options.Handler = handler
+	return NewHTTPHandler(options), nil
+}
@@ -1,12 +1,20 @@
 package nexus
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -62,3 +70,458 @@ func TestWriteFailure_UnsuccessfulOperationError(t *testing.T) {
 	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
 	require.Equal(t, "canceled", failure.Message)
 }
+
+func TestStrictHeaders(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:             &asyncHandler{},
+		StrictHeaders:       true,
+		AllowedNexusHeaders: []string{"Nexus-Custom"},
+	})
+
+	cases := []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{"no nexus headers", http.Header{"Authorization": []string{"x"}}, http.StatusCreated},
+		{"protocol headers", http.Header{"Nexus-Request-Id": []string{"x"}, "Nexus-Callback-Foo": []string{"bar"}}, http.StatusCreated},
+		{"allowed header", http.Header{"Nexus-Custom": []string{"x"}}, http.StatusCreated},
+		{"unknown header", http.Header{"Nexus-Smuggled": []string{"x"}}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/service/operation", nil)
+			request.Header = c.header
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, request)
+			require.Equal(t, c.status, writer.Code)
+		})
+	}
+}
+
+type longOperationIDHandler struct {
+	UnimplementedHandler
+}
+
+func (h *longOperationIDHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultAsync{OperationID: strings.Repeat("a", 11)}, nil
+}
+
+func (h *longOperationIDHandler) GetOperationInfo(ctx context.Context, service, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	return &OperationInfo{ID: operationID, State: OperationStateRunning}, nil
+}
+
+func TestMaxOperationIDLength(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:              &longOperationIDHandler{},
+		MaxOperationIDLength: 10,
+	})
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/service/operation", nil))
+	require.Equal(t, http.StatusInternalServerError, writer.Code)
+
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", "/service/operation/"+strings.Repeat("a", 10), nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", "/service/operation/"+strings.Repeat("a", 11), nil))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	// Disabled.
+	handler = NewHTTPHandler(HandlerOptions{
+		Handler:              &longOperationIDHandler{},
+		MaxOperationIDLength: -1,
+	})
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/service/operation", nil))
+	require.Equal(t, http.StatusCreated, writer.Code)
+}
+
+type peerCertificateHandler struct {
+	UnimplementedHandler
+}
+
+func (h *peerCertificateHandler) GetOperationInfo(ctx context.Context, service, operation, operationID string, options GetOperationInfoOptions) (*OperationInfo, error) {
+	cert := ExtractPeerCertificate(ctx)
+	if cert == nil {
+		return nil, HandlerErrorf(HandlerErrorTypeUnauthenticated, "no peer certificate")
+	}
+	return &OperationInfo{ID: cert.Subject.CommonName, State: OperationStateRunning}, nil
+}
+
+func TestExtractPeerCertificate(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &peerCertificateHandler{}})
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", "/service/operation/id", nil))
+	require.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "client"}}
+	request := httptest.NewRequest("GET", "/service/operation/id", nil)
+	request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	// Unverified certificates are ignored.
+	require.Equal(t, http.StatusUnauthorized, writer.Code)
+
+	request.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	var info OperationInfo
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &info))
+	require.Equal(t, "client", info.ID)
+}
+
+type rawResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *rawResultHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	var value any
+	switch operation {
+	case "content":
+		value = &Content{Header: Header{"type": "application/octet-stream"}, Data: []byte{0x00, 0x01}}
+	case "content-no-header":
+		value = &Content{Data: []byte{0x00, 0x01}}
+	case "reader":
+		value = &Reader{
+			ReadCloser: io.NopCloser(bytes.NewReader([]byte{0x00, 0x01})),
+			Header:     Header{"type": "application/x-custom"},
+		}
+	}
+	return &HandlerStartOperationResultSync[any]{Value: value}, nil
+}
+
+func TestWriteResult_RawContentBypassesSerializer(t *testing.T) {
+	serializer := &customSerializer{}
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:    &rawResultHandler{},
+		Serializer: serializer,
+	})
+
+	cases := []struct {
+		operation   string
+		contentType string
+	}{
+		{operation: "content", contentType: "application/octet-stream"},
+		// Sniffed by net/http when no content type is set.
+		{operation: "content-no-header", contentType: "application/octet-stream"},
+		{operation: "reader", contentType: "application/x-custom"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.operation, func(t *testing.T) {
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, httptest.NewRequest("POST", "/svc/"+c.operation, nil))
+			require.Equal(t, http.StatusOK, writer.Code)
+			require.Equal(t, c.contentType, writer.Header().Get("Content-Type"))
+			require.Equal(t, []byte{0x00, 0x01}, writer.Body.Bytes())
+		})
+	}
+	require.Equal(t, 0, serializer.encoded)
+}
+
+func TestNewSingleOperationHTTPHandler(t *testing.T) {
+	handler, err := NewSingleOperationHTTPHandler(testService, numberValidatorOperation, HandlerOptions{})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client, err := NewHTTPClient(HTTPClientOptions{BaseURL: server.URL, Service: testService})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, err := ExecuteOperation(ctx, client, numberValidatorOperation, 3, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 3, result)
+
+	_, err = client.ExecuteOperation(ctx, "unknown", nil, ExecuteOperationOptions{})
+	var handlerError *HandlerError
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, HandlerErrorTypeNotFound, handlerError.Type)
+
+	_, err = NewSingleOperationHTTPHandler("", numberValidatorOperation, HandlerOptions{})
+	require.ErrorContains(t, err, "no name")
+}
+
+type blockingStartHandler struct {
+	UnimplementedHandler
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingStartHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	var body []byte
+	if err := input.Consume(&body); err != nil {
+		return nil, err
+	}
+	h.started <- struct{}{}
+	<-h.release
+	return &HandlerStartOperationResultSync[any]{Value: body}, nil
+}
+
+func TestMaxInFlightRequestBytes(t *testing.T) {
+	handler := &blockingStartHandler{started: make(chan struct{}), release: make(chan struct{})}
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler:                 handler,
+		MaxInFlightRequestBytes: 10,
+	})
+	newRequest := func(size int) *http.Request {
+		request := httptest.NewRequest("POST", "/svc/op", bytes.NewReader(make([]byte, size)))
+		request.Header.Set("Content-Type", "application/octet-stream")
+		return request
+	}
+
+	// A single request larger than the limit can never succeed and is rejected as a bad request.
+	writer := httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, newRequest(11))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.Contains(t, writer.Body.String(), "exceeds max in flight size of 10 bytes")
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		httpHandler.ServeHTTP(first, newRequest(8))
+	}()
+	<-handler.started
+
+	// The first request holds 8 bytes, leaving no room for another 8.
+	writer = httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, newRequest(8))
+	require.Equal(t, http.StatusTooManyRequests, writer.Code)
+
+	close(handler.release)
+	<-done
+	require.Equal(t, http.StatusOK, first.Code)
+
+	// Bytes are released once the first request completes.
+	go func() { <-handler.started }()
+	writer = httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, newRequest(8))
+	require.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestMaxInFlightRequestBytes_UnknownLength(t *testing.T) {
+	handler := &blockingStartHandler{started: make(chan struct{}, 1), release: make(chan struct{})}
+	close(handler.release)
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler:                 handler,
+		MaxInFlightRequestBytes: 10,
+	})
+
+	request := httptest.NewRequest("POST", "/svc/op", io.NopCloser(bytes.NewReader(make([]byte, 11))))
+	request.ContentLength = -1
+	request.Header.Set("Content-Type", "application/octet-stream")
+	writer := httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusTooManyRequests, writer.Code)
+}
+
+func TestMaxRequestBodySize(t *testing.T) {
+	svc := NewService("svc")
+	require.NoError(t, svc.Register(bytesIOOperation))
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler:            handler,
+		MaxRequestBodySize: 10,
+	})
+	newRequest := func(body io.Reader) *http.Request {
+		request := httptest.NewRequest("POST", "/svc/bytes-io", body)
+		request.Header.Set("Content-Type", "application/octet-stream")
+		return request
+	}
+
+	// Declared length exceeds the limit.
+	writer := httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, newRequest(bytes.NewReader(make([]byte, 11))))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.Contains(t, writer.Body.String(), "request body exceeds max size of 10 bytes")
+
+	// Unknown length, the limit is enforced when reading the input.
+	writer = httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, newRequest(io.MultiReader(bytes.NewReader(make([]byte, 11)))))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	writer = httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, newRequest(bytes.NewReader(make([]byte, 10))))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, append(make([]byte, 10), ", world"...), writer.Body.Bytes())
+}
+
+func TestInputSink(t *testing.T) {
+	svc := NewService("svc")
+	require.NoError(t, svc.Register(bytesIOOperation))
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	var archived bytes.Buffer
+	var archivedInfo HandlerInfo
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler: handler,
+		InputSink: func(ctx context.Context, info HandlerInfo, input io.Reader) io.Reader {
+			archivedInfo = info
+			return io.TeeReader(input, &archived)
+		},
+	})
+
+	request := httptest.NewRequest("POST", "/svc/bytes-io", strings.NewReader("hello"))
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("tenant", "acme")
+	writer := httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "hello, world", writer.Body.String())
+	require.Equal(t, "hello", archived.String())
+	require.Equal(t, "svc", archivedInfo.Service)
+	require.Equal(t, "bytes-io", archivedInfo.Operation)
+	require.Equal(t, "acme", archivedInfo.Header.Get("tenant"))
+}
+
+type blockingResultHandler struct {
+	UnimplementedHandler
+	started chan struct{}
+}
+
+func (h *blockingResultHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	close(h.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestBaseContext_CancelsLongPolls(t *testing.T) {
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	handler := &blockingResultHandler{started: make(chan struct{})}
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler:     handler,
+		BaseContext: func() context.Context { return baseCtx },
+	})
+
+	writer := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		httpHandler.ServeHTTP(writer, httptest.NewRequest("GET", "/svc/op/id/result?wait=10s", nil))
+	}()
+	<-handler.started
+	cancelBase()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("long poll did not return after base context was canceled")
+	}
+	require.Equal(t, http.StatusRequestTimeout, writer.Code)
+}
+
+func TestServiceDiscovery(t *testing.T) {
+	svc := NewService("svc")
+	require.NoError(t, svc.Register(numberValidatorOperation, bytesIOOperation))
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+
+	writer := httptest.NewRecorder()
+	NewHTTPHandler(HandlerOptions{Handler: handler}).ServeHTTP(writer, httptest.NewRequest("GET", "/_services", nil))
+	require.Equal(t, http.StatusNotFound, writer.Code)
+
+	writer = httptest.NewRecorder()
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: handler, EnableServiceDiscovery: true})
+	httpHandler.ServeHTTP(writer, httptest.NewRequest("GET", "/_services", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, contentTypeJSON, writer.Header().Get("Content-Type"))
+	var document struct {
+		Services []ServiceDescription `json:"services"`
+	}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &document))
+	require.Equal(t, []ServiceDescription{{
+		Name: "svc",
+		Operations: []OperationDescription{
+			{Name: "bytes-io", InputType: "[]uint8", OutputType: "[]uint8"},
+			{Name: "number-validator", InputType: "int", OutputType: "int"},
+		},
+	}}, document.Services)
+}
+
+func TestTrimTrailingSlash(t *testing.T) {
+	svc := NewService("svc")
+	require.NoError(t, svc.Register(numberValidatorOperation, asyncNumberValidatorOperationInstance))
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+	send := func(httpHandler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", contentTypeJSON)
+		writer := httptest.NewRecorder()
+		httpHandler.ServeHTTP(writer, request)
+		return writer
+	}
+
+	// Routed as a request for an operation with an empty ID.
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: handler})
+	require.Equal(t, http.StatusBadRequest, send(httpHandler, "POST", "/svc/number-validator/", "3").Code)
+
+	httpHandler = NewHTTPHandler(HandlerOptions{Handler: handler, TrimTrailingSlash: true})
+	writer := send(httpHandler, "POST", "/svc/number-validator/", "3")
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "3", writer.Body.String())
+	writer = send(httpHandler, "GET", "/svc/async-number-validator/3/", "")
+	require.Equal(t, http.StatusOK, writer.Code)
+	writer = send(httpHandler, "GET", "/svc/async-number-validator/3/result//", "")
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "3", writer.Body.String())
+}
+
+func TestCaseInsensitiveOperations(t *testing.T) {
+	svc := NewService("svc")
+	require.NoError(t, svc.Register(
+		numberValidatorOperation,
+		NewSyncOperation("Echo", func(ctx context.Context, input int, options StartOperationOptions) (int, error) {
+			return input, nil
+		}),
+		NewSyncOperation("echo", func(ctx context.Context, input int, options StartOperationOptions) (int, error) {
+			return -input, nil
+		}),
+	))
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.Register(svc))
+	handler, err := registry.NewHandler()
+	require.NoError(t, err)
+	send := func(httpHandler http.Handler, path string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("POST", path, strings.NewReader("3"))
+		request.Header.Set("Content-Type", contentTypeJSON)
+		writer := httptest.NewRecorder()
+		httpHandler.ServeHTTP(writer, request)
+		return writer
+	}
+
+	httpHandler := NewHTTPHandler(HandlerOptions{Handler: handler})
+	require.Equal(t, http.StatusNotFound, send(httpHandler, "/svc/Number-Validator").Code)
+
+	httpHandler = NewHTTPHandler(HandlerOptions{Handler: handler, CaseInsensitiveOperations: true})
+	writer := send(httpHandler, "/svc/Number-Validator")
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "3", writer.Body.String())
+
+	// Exact matches take precedence.
+	writer = send(httpHandler, "/svc/Echo")
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "3", writer.Body.String())
+	writer = send(httpHandler, "/svc/echo")
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "-3", writer.Body.String())
+
+	// Ambiguous names are not resolved.
+	require.Equal(t, http.StatusNotFound, send(httpHandler, "/svc/ECHO").Code)
+}
@@ -0,0 +1,155 @@
+package nexus
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderCompletionSignature carries the signature of a completion request, see [HMACCompletionSigner].
+const HeaderCompletionSignature = "nexus-completion-signature"
+
+// HeaderCompletionTimestamp carries the time a completion request was signed at, in seconds since the Unix epoch, see
+// [HMACCompletionSigner].
+const HeaderCompletionTimestamp = "nexus-completion-timestamp"
+
+const hmacSignaturePrefix = "sha256="
+
+const defaultHMACCompletionTolerance = 5 * time.Minute
+
+var errEmptyHMACKey = errors.New("HMACCompletionSigner key is empty")
+
+// CompletionSigner signs completion requests before they are delivered, see
+// [OperationCompletionSuccessfulOptions.Signer] and [OperationCompletionUnsuccessfulOptions.Signer].
+type CompletionSigner interface {
+	// SignCompletion adds a signature to the given request. It is called once all other headers and the body are set.
+	SignCompletion(*http.Request) error
+}
+
+// CompletionVerifier verifies completion requests before they are passed to a [CompletionHandler], see
+// [CompletionHandlerOptions.Verifier].
+type CompletionVerifier interface {
+	// VerifyCompletion returns an error if the request is not authentic. Return a [HandlerError] to control the error
+	// type, other errors are reported as [HandlerErrorTypeUnauthenticated]. Implementations that read the body must
+	// replace it for the handler to read.
+	VerifyCompletion(*http.Request) error
+}
+
+// HMACCompletionSigner signs and verifies completion requests with an HMAC-SHA256 keyed with a secret shared between
+// the sender and the receiver of completions.
+//
+// The signature is sent in the [HeaderCompletionSignature] header as "sha256=" followed by the hex encoded HMAC of
+// the following lines, separated by "\n": the [HeaderCompletionTimestamp], Content-Type, Nexus-Operation-State,
+// Nexus-Operation-Id, Nexus-Operation-Start-Time, and Nexus-Link header values (empty if not set, multiple values
+// joined by ","), followed by the raw request body. Other headers and the URL are not covered.
+//
+// The signing time is sent in the [HeaderCompletionTimestamp] header. Requests signed outside of the Tolerance window
+// are rejected, limiting the time a captured request can be replayed for.
+//
+// Signing and verifying buffer the request body in memory.
+type HMACCompletionSigner struct {
+	// Shared secret key. Required, signing and verifying fail if empty.
+	Key []byte
+	// Maximum difference between the signing time of a request and the time it is verified at, in either direction to
+	// allow for clock skew. Defaults to 5 minutes.
+	Tolerance time.Duration
+
+	// Returns the current time, for testing.
+	now func() time.Time
+}
+
+// SignCompletion implements CompletionSigner.
+func (s *HMACCompletionSigner) SignCompletion(request *http.Request) error {
+	if len(s.Key) == 0 {
+		return errEmptyHMACKey
+	}
+	body, err := readAndReplaceRequestBody(request)
+	if err != nil {
+		return err
+	}
+	request.ContentLength = int64(len(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	request.Header.Set(HeaderCompletionTimestamp, strconv.FormatInt(s.currentTime().Unix(), 10))
+	request.Header.Set(HeaderCompletionSignature, hmacSignaturePrefix+hex.EncodeToString(s.sign(request.Header, body)))
+	return nil
+}
+
+// VerifyCompletion implements CompletionVerifier.
+func (s *HMACCompletionSigner) VerifyCompletion(request *http.Request) error {
+	if len(s.Key) == 0 {
+		return errEmptyHMACKey
+	}
+	signature := request.Header.Get(HeaderCompletionSignature)
+	if signature == "" {
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "missing %q header", HeaderCompletionSignature)
+	}
+	mac, err := hex.DecodeString(strings.TrimPrefix(signature, hmacSignaturePrefix))
+	if err != nil || !strings.HasPrefix(signature, hmacSignaturePrefix) {
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "invalid %q header", HeaderCompletionSignature)
+	}
+	body, err := readAndReplaceRequestBody(request)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return HandlerErrorf(HandlerErrorTypeBadRequest, "request body exceeds max size of %d bytes", maxBytesErr.Limit)
+		}
+		return HandlerErrorf(HandlerErrorTypeBadRequest, "failed to read request body")
+	}
+	if !hmac.Equal(mac, s.sign(request.Header, body)) {
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "invalid completion signature")
+	}
+	// The timestamp is only trusted once the signature covering it is verified.
+	timestamp, err := strconv.ParseInt(request.Header.Get(HeaderCompletionTimestamp), 10, 64)
+	if err != nil {
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "invalid %q header", HeaderCompletionTimestamp)
+	}
+	tolerance := s.Tolerance
+	if tolerance <= 0 {
+		tolerance = defaultHMACCompletionTolerance
+	}
+	if skew := s.currentTime().Sub(time.Unix(timestamp, 0)); skew > tolerance || skew < -tolerance {
+		return HandlerErrorf(HandlerErrorTypeUnauthenticated, "completion signature expired")
+	}
+	return nil
+}
+
+func (s *HMACCompletionSigner) currentTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func (s *HMACCompletionSigner) sign(header http.Header, body []byte) []byte {
+	h := hmac.New(sha256.New, s.Key)
+	for _, key := range []string{HeaderCompletionTimestamp, "Content-Type", headerOperationState, HeaderOperationID, headerOperationStartTime, headerLink} {
+		h.Write([]byte(strings.Join(header.Values(key), ",")))
+		h.Write([]byte("\n"))
+	}
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// readAndReplaceRequestBody reads the request body in its entirety and closes it, and then replaces it with an
+// in-memory buffer.
+func readAndReplaceRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
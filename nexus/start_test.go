@@ -3,9 +3,14 @@ package nexus
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
 	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -122,6 +127,18 @@ func TestClientRequestID(t *testing.T) {
 	}
 }
 
+func TestClientRequestID_CustomGenerator(t *testing.T) {
+	ctx, client, teardown := setup(t, &requestIDEchoHandler{})
+	defer teardown()
+	client.options.RequestIDGenerator = func() string { return "generated" }
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	var responseBody []byte
+	require.NoError(t, result.Successful.Consume(&responseBody))
+	require.Equal(t, []byte("generated"), responseBody)
+}
+
 type jsonHandler struct {
 	UnimplementedHandler
 }
@@ -347,3 +364,537 @@ func TestStart_NilContentHeaderDoesNotPanic(t *testing.T) {
 	err = response.Consume(&responseBody)
 	require.NoError(t, err)
 }
+
+func TestAsync_LocationHeader(t *testing.T) {
+	for _, emit := range []bool{false, true} {
+		emit := emit
+		t.Run(fmt.Sprintf("emit=%v", emit), func(t *testing.T) {
+			handler := NewHTTPHandler(HandlerOptions{
+				Handler:            &asyncHandler{},
+				EmitLocationHeader: emit,
+			})
+			request := httptest.NewRequest("POST", "/svc/op%2Fname", nil)
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, request)
+			require.Equal(t, http.StatusCreated, writer.Code)
+			if !emit {
+				require.Empty(t, writer.Header().Get("Location"))
+				return
+			}
+			location := writer.Header().Get("Location")
+			require.Equal(t, "./op%2Fname/async", location)
+			base, err := url.Parse("http://example.com/prefix/svc/op%2Fname")
+			require.NoError(t, err)
+			ref, err := url.Parse(location)
+			require.NoError(t, err)
+			// Resolves correctly when mounted under a path prefix.
+			require.Equal(t, "/prefix/svc/op%2Fname/async", base.ResolveReference(ref).EscapedPath())
+		})
+	}
+}
+
+func TestAsync_LocationHeaderResolvesAgainstRequestURL(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:            &asyncHandler{},
+		EmitLocationHeader: true,
+		TrimTrailingSlash:  true,
+	})
+	for _, path := range []string{"/svc/op%2Fname", "/svc/op%2Fname/", "/svc/op%2Fname//"} {
+		request := httptest.NewRequest("POST", "http://example.com"+path, nil)
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		require.Equal(t, http.StatusCreated, writer.Code, path)
+		ref, err := url.Parse(writer.Header().Get("Location"))
+		require.NoError(t, err)
+		require.Equal(t, "/svc/op%2Fname/async", request.URL.ResolveReference(ref).EscapedPath(), path)
+	}
+}
+
+func TestInputTransformer(t *testing.T) {
+	ctx, client, teardown := setup(t, &jsonHandler{})
+	defer teardown()
+
+	client.options.InputTransformer = func(ctx context.Context, input any) (any, error) {
+		s, ok := input.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected input type: %T", input)
+		}
+		return s + " transformed", nil
+	}
+
+	result, err := client.StartOperation(ctx, "foo", "input", StartOperationOptions{})
+	require.NoError(t, err)
+	var output string
+	require.NoError(t, result.Successful.Consume(&output))
+	require.Equal(t, "input transformed", output)
+
+	_, err = client.ExecuteOperation(ctx, "foo", 3, ExecuteOperationOptions{})
+	require.ErrorContains(t, err, "unexpected input type: int")
+}
+
+var syncWithLinksHandlerLink = Link{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/sync"}, Type: "test.Sync"}
+
+type syncWithLinksHandler struct {
+	jsonHandler
+}
+
+func (h *syncWithLinksHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	var s string
+	if err := input.Consume(&s); err != nil {
+		return nil, err
+	}
+	return &HandlerStartOperationResultSync[any]{Value: s, Links: []Link{syncWithLinksHandlerLink}}, nil
+}
+
+func TestStartOperationHandle_Sync(t *testing.T) {
+	ctx, client, teardown := setup(t, &syncWithLinksHandler{})
+	defer teardown()
+
+	handle, result, err := client.StartOperationHandle(ctx, "foo", "success", StartOperationOptions{})
+	require.NoError(t, err)
+	require.Empty(t, handle.ID)
+	require.Equal(t, []Link{syncWithLinksHandlerLink}, result.Links)
+
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateSucceeded, info.State)
+	_, err = handle.Cancel(ctx, CancelOperationOptions{})
+	require.NoError(t, err)
+	require.ErrorIs(t, handle.RegisterCallback(ctx, RegisterOperationCallbackOptions{CallbackURL: "http://test"}), errOperationAlreadyCompleted)
+
+	value, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	var output string
+	require.NoError(t, value.Consume(&output))
+	require.Equal(t, "success", output)
+
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorIs(t, err, errResultAlreadyConsumed)
+}
+
+func TestStartOperationHandle_Async(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncHandler{})
+	defer teardown()
+
+	handle, result, err := client.StartOperationHandle(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "async", handle.ID)
+	require.Same(t, result.Pending, handle)
+	require.False(t, handle.resolved)
+}
+
+func TestClientTrace(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithInfoHandler{})
+	defer teardown()
+
+	var conns []httptrace.GotConnInfo
+	client.options.ClientTrace = &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			conns = append(conns, info)
+		},
+	}
+
+	result, err := client.StartOperation(ctx, "escape/me", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Len(t, conns, 2)
+	require.True(t, conns[1].Reused)
+}
+
+func TestLazyValue_WriteTo(t *testing.T) {
+	ctx, client, teardown := setup(t, &successHandler{})
+	defer teardown()
+
+	requestBody := bytes.Repeat([]byte{0x00, 0x01}, 1024)
+	response, err := client.ExecuteOperation(ctx, "i need to/be escaped", requestBody, ExecuteOperationOptions{
+		CallbackURL:    "http://test/callback",
+		CallbackHeader: Header{"callback-test": "ok"},
+		Header:         Header{"test": "ok"},
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	n, err := response.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(requestBody)), n)
+	require.Equal(t, requestBody, buf.Bytes())
+}
+
+type largeResultHandler struct {
+	UnimplementedHandler
+	result []byte
+}
+
+func (h *largeResultHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultSync[any]{Value: h.result}, nil
+}
+
+func TestLazyValue_Stream(t *testing.T) {
+	expected := bytes.Repeat([]byte("0123456789abcdef"), 512*1024)
+	ctx, client, teardown := setup(t, &largeResultHandler{result: expected})
+	defer teardown()
+
+	response, err := client.ExecuteOperation(ctx, "foo", nil, ExecuteOperationOptions{})
+	require.NoError(t, err)
+	stream, header, err := response.Stream()
+	require.NoError(t, err)
+	require.Equal(t, "application/octet-stream", header.Get("type"))
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, stream)
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	require.Equal(t, int64(len(expected)), n)
+	require.Equal(t, expected, buf.Bytes())
+
+	require.ErrorIs(t, response.Consume(new([]byte)), errValueAlreadyConsumed)
+	_, _, err = response.Stream()
+	require.ErrorIs(t, err, errValueAlreadyConsumed)
+}
+
+type rejectingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *rejectingHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return nil, &OperationRejectedError{Cause: fmt.Errorf("precondition not met")}
+}
+
+func TestRejected(t *testing.T) {
+	ctx, client, teardown := setup(t, &rejectingHandler{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	var rejectedError *OperationRejectedError
+	require.ErrorAs(t, err, &rejectedError)
+	require.Equal(t, "precondition not met", rejectedError.Cause.Error())
+	require.Equal(t, "operation rejected: precondition not met", err.Error())
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.False(t, errors.As(err, &unsuccessfulError))
+	var handlerError *HandlerError
+	require.False(t, errors.As(err, &handlerError))
+}
+
+func TestStart_AbsoluteDeadline(t *testing.T) {
+	ctx, client, teardown := setup(t, &timeoutEchoHandler{})
+	defer teardown()
+
+	var deadlineHeader string
+	client.options.SendAbsoluteDeadline = true
+	client.options.HTTPCaller = func(r *http.Request) (*http.Response, error) {
+		deadlineHeader = r.Header.Get(headerDeadline)
+		return http.DefaultClient.Do(r)
+	}
+
+	deadline, _ := ctx.Deadline()
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	requireTimeoutPropagated(t, result, time.Until(deadline))
+	parsed, err := time.Parse(time.RFC3339Nano, deadlineHeader)
+	require.NoError(t, err)
+	require.True(t, deadline.Equal(parsed))
+}
+
+func TestStart_AbsoluteDeadlineOverridesLongerRequestTimeout(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:                    &timeoutEchoHandler{},
+		DeadlineClockSkewTolerance: 100 * time.Millisecond,
+	})
+
+	request := httptest.NewRequest("POST", "/svc/op", nil)
+	request.Header.Set(HeaderRequestTimeout, "10s")
+	request.Header.Set(headerDeadline, time.Now().Add(time.Second).Format(time.RFC3339Nano))
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	timeout, err := parseDuration(writer.Body.String())
+	require.NoError(t, err)
+	require.Greater(t, timeout, time.Second)
+	require.LessOrEqual(t, timeout, 1100*time.Millisecond)
+
+	request = httptest.NewRequest("POST", "/svc/op", nil)
+	request.Header.Set(headerDeadline, "not a deadline")
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+type requestTimeoutEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *requestTimeoutEchoHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	timeout, ok := ExtractRequestTimeout(ctx)
+	if !ok {
+		return &HandlerStartOperationResultSync[any]{Value: []byte("not set")}, nil
+	}
+	return &HandlerStartOperationResultSync[any]{Value: []byte(formatDuration(timeout))}, nil
+}
+
+func TestStart_ExtractRequestTimeout(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &requestTimeoutEchoHandler{}})
+
+	request := httptest.NewRequest("POST", "/svc/op", nil)
+	request.Header.Set(HeaderRequestTimeout, "10s")
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "10000ms", writer.Body.String())
+
+	request = httptest.NewRequest("POST", "/svc/op", nil)
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "not set", writer.Body.String())
+}
+
+func TestStart_OperationTimeouts(t *testing.T) {
+	ctx, client, teardown := setup(t, &timeoutEchoHandler{})
+	defer teardown()
+	client.options.OperationTimeouts = map[string]time.Duration{"fast": time.Second, "exempt": -1}
+	client.options.DefaultOperationTimeout = 2 * time.Second
+
+	result, err := client.StartOperation(ctx, "fast", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	requireTimeoutPropagated(t, result, time.Second)
+
+	result, err = client.StartOperation(ctx, "other", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	response := result.Successful
+	var responseBody []byte
+	require.NoError(t, response.Consume(&responseBody))
+	timeout, err := parseDuration(string(responseBody))
+	require.NoError(t, err)
+	require.Greater(t, timeout, time.Second)
+	require.LessOrEqual(t, timeout, 2*time.Second)
+
+	deadline, _ := ctx.Deadline()
+	result, err = client.StartOperation(ctx, "exempt", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	response = result.Successful
+	require.NoError(t, response.Consume(&responseBody))
+	timeout, err = parseDuration(string(responseBody))
+	require.NoError(t, err)
+	require.Greater(t, timeout, 2*time.Second)
+	require.LessOrEqual(t, timeout, time.Until(deadline))
+}
+
+type blockingBody struct {
+	ctx    context.Context
+	closed bool
+}
+
+func (b *blockingBody) Read(p []byte) (int, error) {
+	<-b.ctx.Done()
+	return 0, errors.New("connection reset")
+}
+
+func (b *blockingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestStart_RequestCanceled(t *testing.T) {
+	client, err := NewHTTPClient(HTTPClientOptions{
+		BaseURL: "http://localhost/",
+		Service: testService,
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			<-request.Context().Done()
+			return nil, errors.New("connection reset")
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = client.StartOperation(ctx, "op", nil, StartOperationOptions{})
+	require.ErrorIs(t, err, ErrRequestCanceled)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotErrorIs(t, err, ErrOperationStillRunning)
+	require.ErrorContains(t, err, "connection reset")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.StartOperation(ctx, "op", nil, StartOperationOptions{})
+	require.ErrorIs(t, err, ErrRequestCanceled)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestStart_RequestCanceledWhileReadingResult(t *testing.T) {
+	var body *blockingBody
+	client, err := NewHTTPClient(HTTPClientOptions{
+		BaseURL: "http://localhost/",
+		Service: testService,
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			body = &blockingBody{ctx: request.Context()}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result, err := client.StartOperation(ctx, "op", nil, StartOperationOptions{})
+	require.NoError(t, err)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	// Read without consuming to verify that the body is closed on cancelation.
+	_, err = io.ReadAll(result.Successful.Reader)
+	require.ErrorIs(t, err, ErrRequestCanceled)
+	require.ErrorIs(t, err, context.Canceled)
+	require.True(t, body.closed)
+}
+
+type headerEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *headerEchoHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return &HandlerStartOperationResultSync[any]{Value: map[string]Header{
+		"header":         options.Header,
+		"callbackHeader": options.CallbackHeader,
+	}}, nil
+}
+
+func (h *headerEchoHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) (*CancelOperationResult, error) {
+	if options.Header.Get("x-tenant") != "default" {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "invalid 'x-tenant' header: %q", options.Header.Get("x-tenant"))
+	}
+	return nil, nil
+}
+
+func TestClientDefaultHeader(t *testing.T) {
+	ctx, client, teardown := setup(t, &headerEchoHandler{})
+	defer teardown()
+	client.options.DefaultHeader = Header{"x-tenant": "default", "authorization": "token"}
+
+	result, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{
+		Header:         Header{"x-tenant": "override"},
+		CallbackURL:    "http://localhost/callback",
+		CallbackHeader: Header{"x-callback": "value"},
+	})
+	require.NoError(t, err)
+	var echo map[string]Header
+	require.NoError(t, result.Successful.Consume(&echo))
+	require.Equal(t, "override", echo["header"]["x-tenant"])
+	require.Equal(t, "token", echo["header"]["authorization"])
+	require.Equal(t, Header{"x-callback": "value"}, echo["callbackHeader"])
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	_, err = handle.Cancel(ctx, CancelOperationOptions{})
+	require.NoError(t, err)
+}
+
+type retryableFailureHandler struct {
+	UnimplementedHandler
+}
+
+func (h *retryableFailureHandler) failure(operation string) error {
+	err := NewFailedOperationError(errors.New("failed"))
+	switch operation {
+	case "retryable":
+		err.Retryable = new(bool)
+		*err.Retryable = true
+	case "non-retryable":
+		err.Retryable = new(bool)
+	}
+	return err
+}
+
+func (h *retryableFailureHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	return nil, h.failure(operation)
+}
+
+func (h *retryableFailureHandler) GetOperationResult(ctx context.Context, service, operation, operationID string, options GetOperationResultOptions) (any, error) {
+	return nil, h.failure(operation)
+}
+
+func TestUnsuccessful_Retryable(t *testing.T) {
+	ctx, client, teardown := setup(t, &retryableFailureHandler{})
+	defer teardown()
+
+	for operation, expected := range map[string]*bool{
+		"retryable":     func() *bool { b := true; return &b }(),
+		"non-retryable": func() *bool { b := false; return &b }(),
+		"unset":         nil,
+	} {
+		operation, expected := operation, expected
+		t.Run(operation, func(t *testing.T) {
+			_, err := client.StartOperation(ctx, operation, nil, StartOperationOptions{})
+			var unsuccessfulError *UnsuccessfulOperationError
+			require.ErrorAs(t, err, &unsuccessfulError)
+			require.Equal(t, expected, unsuccessfulError.Retryable)
+
+			handle, err := client.NewHandle(operation, "id")
+			require.NoError(t, err)
+			_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+			require.ErrorAs(t, err, &unsuccessfulError)
+			require.Equal(t, expected, unsuccessfulError.Retryable)
+		})
+	}
+
+	t.Run("OutcomeMapper", func(t *testing.T) {
+		client.options.OutcomeMapper = func(state OperationState, err error) (OperationState, error) {
+			return OperationStateCanceled, fmt.Errorf("mapped: %w", err)
+		}
+		defer func() { client.options.OutcomeMapper = nil }()
+
+		handle, err := client.NewHandle("retryable", "id")
+		require.NoError(t, err)
+		_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+		var unsuccessfulError *UnsuccessfulOperationError
+		require.ErrorAs(t, err, &unsuccessfulError)
+		require.Equal(t, OperationStateCanceled, unsuccessfulError.State)
+		require.EqualError(t, unsuccessfulError.Cause, "mapped: failed")
+		require.NotNil(t, unsuccessfulError.Retryable)
+		require.True(t, *unsuccessfulError.Retryable)
+	})
+}
+
+type dryRunCountingHandler struct {
+	UnimplementedHandler
+	started atomic.Int32
+}
+
+func (h *dryRunCountingHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	h.started.Add(1)
+	if options.DryRun {
+		return &HandlerStartOperationResultDryRun{}, nil
+	}
+	return &HandlerStartOperationResultAsync{OperationID: "id"}, nil
+}
+
+type dryRunSupportingHandler struct {
+	dryRunCountingHandler
+}
+
+func (h *dryRunSupportingHandler) SupportsDryRun(service, operation string) bool {
+	return operation == "supported"
+}
+
+func TestStart_DryRunNotSupported(t *testing.T) {
+	handler := &dryRunCountingHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, "foo", nil, StartOperationOptions{DryRun: true})
+	var handlerErr *HandlerError
+	require.ErrorAs(t, err, &handlerErr)
+	require.Equal(t, HandlerErrorTypeNotImplemented, handlerErr.Type)
+	require.Equal(t, int32(0), handler.started.Load())
+}
+
+func TestStart_DryRunHandler(t *testing.T) {
+	handler := &dryRunSupportingHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, "supported", nil, StartOperationOptions{DryRun: true})
+	require.NoError(t, err)
+	require.True(t, result.DryRun)
+	require.Equal(t, int32(1), handler.started.Load())
+
+	_, err = client.StartOperation(ctx, "unsupported", nil, StartOperationOptions{DryRun: true})
+	var handlerErr *HandlerError
+	require.ErrorAs(t, err, &handlerErr)
+	require.Equal(t, HandlerErrorTypeNotImplemented, handlerErr.Type)
+	require.Equal(t, int32(1), handler.started.Load())
+}
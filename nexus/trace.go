@@ -0,0 +1,41 @@
+package nexus
+
+// TraceContext holds the W3C trace context propagated in a [Header].
+//
+// It is independent of any particular tracing SDK, use the SDK's propagator to convert it to and from a span context.
+//
+// See https://www.w3.org/TR/trace-context/.
+type TraceContext struct {
+	// Value of the traceparent header.
+	TraceParent string
+	// Value of the tracestate header. Optional, carries vendor specific trace information such as sampling decisions.
+	TraceState string
+}
+
+// ExtractTraceContext extracts the W3C trace context from the given header, e.g. the header of an incoming request or
+// the callback header of a start operation request. Returns false if the header has no traceparent.
+//
+// Per the W3C spec, a tracestate without a traceparent is ignored.
+func ExtractTraceContext(header Header) (TraceContext, bool) {
+	traceParent := header.Get(HeaderTraceParent)
+	if traceParent == "" {
+		return TraceContext{}, false
+	}
+	return TraceContext{TraceParent: traceParent, TraceState: header.Get(HeaderTraceState)}, true
+}
+
+// InjectTraceContext sets the W3C trace context headers on the given header, e.g. the header or callback header of
+// start operation options, or the header of an operation completion. Does nothing if the context has no traceparent.
+//
+// Any existing tracestate is removed if the given context has none to avoid propagating a stale vendor state.
+func InjectTraceContext(header Header, traceContext TraceContext) {
+	if traceContext.TraceParent == "" {
+		return
+	}
+	header.Set(HeaderTraceParent, traceContext.TraceParent)
+	if traceContext.TraceState == "" {
+		delete(header, HeaderTraceState)
+		return
+	}
+	header.Set(HeaderTraceState, traceContext.TraceState)
+}
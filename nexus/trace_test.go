@@ -0,0 +1,66 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type traceContextHandler struct {
+	UnimplementedHandler
+}
+
+func (h *traceContextHandler) StartOperation(ctx context.Context, service, operation string, input *LazyValue, options StartOperationOptions) (HandlerStartOperationResult[any], error) {
+	traceContext, ok := ExtractTraceContext(options.Header)
+	if !ok {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "missing trace context")
+	}
+	callbackTraceContext, ok := ExtractTraceContext(options.CallbackHeader)
+	if !ok {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "missing callback trace context")
+	}
+	if traceContext != callbackTraceContext {
+		return nil, HandlerErrorf(HandlerErrorTypeBadRequest, "trace context mismatch: %v != %v", traceContext, callbackTraceContext)
+	}
+	return &HandlerStartOperationResultSync[any]{Value: traceContext.TraceParent + ";" + traceContext.TraceState}, nil
+}
+
+func TestTraceContext_Propagation(t *testing.T) {
+	ctx, client, teardown := setup(t, &traceContextHandler{})
+	defer teardown()
+
+	traceContext := TraceContext{
+		TraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		TraceState:  "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+	}
+	options := StartOperationOptions{
+		Header:         Header{},
+		CallbackURL:    "http://test/callback",
+		CallbackHeader: Header{},
+	}
+	InjectTraceContext(options.Header, traceContext)
+	InjectTraceContext(options.CallbackHeader, traceContext)
+
+	result, err := client.StartOperation(ctx, "foo", nil, options)
+	require.NoError(t, err)
+	var output string
+	require.NoError(t, result.Successful.Consume(&output))
+	require.Equal(t, traceContext.TraceParent+";"+traceContext.TraceState, output)
+}
+
+func TestTraceContext_ExtractInject(t *testing.T) {
+	_, ok := ExtractTraceContext(Header{HeaderTraceState: "rojo=00f067aa0ba902b7"})
+	require.False(t, ok)
+
+	header := Header{HeaderTraceState: "stale"}
+	InjectTraceContext(header, TraceContext{})
+	require.Equal(t, Header{HeaderTraceState: "stale"}, header)
+
+	InjectTraceContext(header, TraceContext{TraceParent: "parent"})
+	require.Equal(t, Header{HeaderTraceParent: "parent"}, header)
+
+	traceContext, ok := ExtractTraceContext(Header{"traceparent": "parent", "tracestate": "state"})
+	require.True(t, ok)
+	require.Equal(t, TraceContext{TraceParent: "parent", TraceState: "state"}, traceContext)
+}
@@ -28,10 +28,20 @@ func (h UnimplementedHandler) GetOperationInfo(ctx context.Context, service, ope
 }
 
 // CancelOperation implements the Handler interface.
-func (h UnimplementedHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) error {
+func (h UnimplementedHandler) CancelOperation(ctx context.Context, service, operation, operationID string, options CancelOperationOptions) (*CancelOperationResult, error) {
+	return nil, HandlerErrorf(HandlerErrorTypeNotImplemented, "not implemented")
+}
+
+// RegisterOperationCallback implements the Handler interface.
+func (h UnimplementedHandler) RegisterOperationCallback(ctx context.Context, service, operation, operationID string, options RegisterOperationCallbackOptions) error {
 	return HandlerErrorf(HandlerErrorTypeNotImplemented, "not implemented")
 }
 
+// Close implements the Handler interface.
+func (h UnimplementedHandler) Close(ctx context.Context) error {
+	return nil
+}
+
 // UnimplementedOperation must be embedded into any [Operation] implementation for future compatibility.
 // It implements all methods on the [Operation] interface except for `Name`, returning unimplemented errors if they are
 // not implemented by the embedding type.
@@ -52,7 +62,12 @@ func (*UnimplementedOperation[I, O]) OutputType() reflect.Type {
 }
 
 // Cancel implements Operation.
-func (*UnimplementedOperation[I, O]) Cancel(context.Context, string, CancelOperationOptions) error {
+func (*UnimplementedOperation[I, O]) Cancel(context.Context, string, CancelOperationOptions) (*CancelOperationResult, error) {
+	return nil, HandlerErrorf(HandlerErrorTypeNotImplemented, "not implemented")
+}
+
+// RegisterCallback implements Operation.
+func (*UnimplementedOperation[I, O]) RegisterCallback(context.Context, string, RegisterOperationCallbackOptions) error {
 	return HandlerErrorf(HandlerErrorTypeNotImplemented, "not implemented")
 }
 